@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff calculates jittered exponential delays between reconnect attempts
+type Backoff struct {
+	// Min is the delay before the first retry
+	Min time.Duration
+	// Max caps the delay between retries
+	Max time.Duration
+	// Factor is the multiplier applied for each failed attempt
+	Factor float64
+	// Jitter is the fraction of the delay which is randomised, between 0 and 1
+	Jitter float64
+}
+
+// DefaultBackoff is used when no backoff is configured
+var DefaultBackoff = Backoff{
+	Min:    time.Second,
+	Max:    2 * time.Minute,
+	Factor: 2,
+	Jitter: 0.2,
+}
+
+// Duration returns how long to wait before the given attempt, starting at 0
+func (b Backoff) Duration(attempt int) time.Duration {
+	if b.Min <= 0 {
+		return 0
+	}
+	factor := b.Factor
+	if factor < 1 {
+		factor = 1
+	}
+	dur := float64(b.Min) * math.Pow(factor, float64(attempt))
+	if b.Max > 0 && dur > float64(b.Max) {
+		dur = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		// Spread the delay evenly between dur * (1 - jitter) and dur * (1 + jitter)
+		dur += dur * b.Jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(dur)
+}
+
+// resetAfter is how long a connection has to stay up before the backoff starts again from Min
+func (b Backoff) resetAfter() time.Duration {
+	if b.Max > 0 {
+		return b.Max
+	}
+	return DefaultBackoff.Max
+}
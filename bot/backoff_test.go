@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Duration(t *testing.T) {
+	t.Run("Exponential", func(t *testing.T) {
+		b := Backoff{Min: time.Second, Max: time.Minute, Factor: 2}
+		assert.Equal(t, time.Second, b.Duration(0))
+		assert.Equal(t, 2*time.Second, b.Duration(1))
+		assert.Equal(t, 8*time.Second, b.Duration(3))
+	})
+
+	t.Run("Capped", func(t *testing.T) {
+		b := Backoff{Min: time.Second, Max: 5 * time.Second, Factor: 2}
+		assert.Equal(t, 5*time.Second, b.Duration(10))
+		assert.Equal(t, 5*time.Second, b.Duration(10000))
+	})
+
+	t.Run("Jitter", func(t *testing.T) {
+		b := Backoff{Min: 10 * time.Second, Max: time.Minute, Factor: 2, Jitter: 0.5}
+		for i := 0; i < 100; i++ {
+			d := b.Duration(0)
+			assert.GreaterOrEqual(t, int64(d), int64(5*time.Second))
+			assert.LessOrEqual(t, int64(d), int64(15*time.Second))
+		}
+	})
+
+	t.Run("Zero", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), Backoff{}.Duration(3))
+	})
+}
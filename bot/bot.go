@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/ch629/go-irc-kafka/domain"
 	"github.com/ch629/go-irc-kafka/irc"
 	"github.com/ch629/go-irc-kafka/irc/client"
 	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/ch629/go-irc-kafka/state"
 	"github.com/ch629/go-irc-kafka/twitch"
 	"go.uber.org/zap"
 )
@@ -26,19 +29,41 @@ type Bot struct {
 	messageHandler MessageHandler
 	loginError     chan error
 	logger         *zap.Logger
+	reconnect      chan struct{}
+	state          state.Service
+	joinTimeout    time.Duration
+	capabilities   *capabilities
+	// capabilityTimeout is how long to wait for capability requests to be acknowledged
+	capabilityTimeout time.Duration
+	// nick is the name the bot logged in with, used to find the JOIN & PART echoes for the bot
+	nick atomic.Value
+	// seen is used to drop messages which have already been handled on another connection
+	seen *messageIDs
 
 	loginMux  sync.Mutex
 	loggingIn bool
+	// negotiation is set while LoginSASL is waiting for the server
+	negotiation    *negotiation
+	negotiationMux sync.Mutex
 }
 
 var ErrBadPassword = errors.New("bad password")
 
+// DefaultJoinTimeout is how long JoinChannelResults waits for each channel to be confirmed
+const DefaultJoinTimeout = 10 * time.Second
+
 func New(irc IRCReadWriter, messageHandler MessageHandler) *Bot {
 	return &Bot{
 		ircReadWriter:  irc,
 		errors:         make(chan error),
 		messageHandler: messageHandler,
 		logger:         zap.L(),
+		reconnect:      make(chan struct{}, 1),
+		state:          state.NewService(),
+		joinTimeout:    DefaultJoinTimeout,
+		capabilities:   newCapabilities(),
+
+		capabilityTimeout: DefaultCapabilityTimeout,
 	}
 }
 
@@ -53,53 +78,92 @@ func (b *Bot) ProcessMessages(ctx context.Context) {
 			if !ok {
 				return
 			}
-			switch message.Command {
-			case irc.Ping:
-				if err := b.ircReadWriter.Send(twitch.MakePongCommand(message.Params[0])); err != nil {
-					b.errors <- fmt.Errorf("failed to send PONG: %w", err)
-				}
-			case irc.PrivateMessage:
-				if b.messageHandler.onPrivateMessage == nil {
-					continue
-				}
-				msg, err := domain.MakeChatMessage(message)
-				if err != nil {
-					b.errors <- fmt.Errorf("failed to map chat message %w", err)
-					continue
-				}
-
-				b.messageHandler.onPrivateMessage(*msg)
-			case irc.ClearChat:
-				if b.messageHandler.onBan == nil {
-					continue
-				}
-				ban, err := domain.NewBan(message)
-				if err != nil {
-					b.errors <- fmt.Errorf("failed to map ban message %w", err)
-					continue
-				}
-				b.messageHandler.onBan(*ban)
-			case irc.EndOfMOTD:
-				// Connected & ready to join channels
-				if b.loggingIn {
-					b.loginError <- nil
-				}
-			// ERR_PASSWDMISMATCH
-			case irc.ErrPasswordMismatch:
-				if b.loggingIn {
-					b.loginError <- ErrBadPassword
-				}
-			// Ignored messages
-			case "001", "002", "003", "004", "375", "372", "353", "366":
-			default:
-				log.Info("received unhandled command", zap.String("command", message.Command), zap.String("message", fmt.Sprintf("%+v", message)))
+			if b.seen != nil && !b.seen.Add(message.Tags["id"]) {
+				continue
+			}
+			core := b.handleCore(message)
+			if handled := b.messageHandler.dispatch(message, b.error); handled || core {
+				continue
+			}
+			// Error numerics are reported, any other numeric is informational
+			if err := irc.NumericErr(message); err != nil {
+				b.error(err)
+				continue
 			}
+			if irc.IsNumeric(message.Command) {
+				log.Debug("received numeric", zap.String("numeric", irc.Numeric(message.Command).Name()))
+				continue
+			}
+			log.Info("received unhandled command", zap.String("command", message.Command), zap.String("message", fmt.Sprintf("%+v", message)))
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// handleCore handles the messages the bot itself depends on, returning false if the command isn't one of them
+func (b *Bot) handleCore(message parser.Message) bool {
+	if b.forwardNegotiation(message) {
+		return true
+	}
+	switch message.Command {
+	case irc.Ping:
+		var server string
+		if len(message.Params) > 0 {
+			server = message.Params[0]
+		}
+		if err := b.ircReadWriter.Send(twitch.MakePongCommand(server)); err != nil {
+			b.error(fmt.Errorf("failed to send PONG: %w", err))
+		}
+	case string(irc.RplEndOfMOTD):
+		// Connected & ready to join channels
+		if b.loggingIn {
+			b.loginError <- nil
+		}
+	case string(irc.ErrPasswordMismatch):
+		if b.loggingIn {
+			b.loginError <- ErrBadPassword
+		}
+	case irc.Reconnect:
+		select {
+		case b.reconnect <- struct{}{}:
+		default:
+		}
+	case irc.Join:
+		if b.isSelf(message) {
+			b.state.JoinChannel(message.Params.Channel())
+		}
+	// ROOMSTATE is only sent for channels we've joined
+	case irc.RoomState:
+		if len(message.Params) > 0 {
+			b.state.JoinChannel(message.Params.Channel())
+		}
+	case irc.Notice:
+		return b.handleJoinNotice(message)
+	case irc.Capability:
+		b.handleCapability(message)
+	case irc.Part:
+		if b.isSelf(message) {
+			if err := b.state.LeaveChannel(message.Params.Channel()); err != nil {
+				b.error(fmt.Errorf("failed to leave channel %v: %w", message.Params.Channel(), err))
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// isSelf returns whether the message was sent by the bot itself
+func (b *Bot) isSelf(message parser.Message) bool {
+	nick, _ := b.nick.Load().(string)
+	return len(nick) > 0 && len(message.Params) > 0 && strings.EqualFold(message.Prefix.User(), nick)
+}
+
+func (b *Bot) error(err error) {
+	b.errors <- err
+}
+
 // Login logs into the IRC server using the name and password, blocking until either the login was successful, fails or the context is cancelled
 func (b *Bot) Login(ctx context.Context, name, pass string) error {
 	// TODO: Write some tests around getting login errors after we're done logging in etc
@@ -111,6 +175,7 @@ func (b *Bot) Login(ctx context.Context, name, pass string) error {
 		close(b.loginError)
 		b.loginMux.Unlock()
 	}()
+	b.nick.Store(name)
 	if err := b.ircReadWriter.Send(twitch.MakePassCommand(pass), twitch.MakeNickCommand(name)); err != nil {
 		return err
 	}
@@ -125,6 +190,7 @@ func (b *Bot) Login(ctx context.Context, name, pass string) error {
 	return nil
 }
 
+// State is the channels the bot has joined on this connection
 func (b *Bot) JoinChannels(channels ...string) error {
 	for _, ch := range channels {
 		if err := b.ircReadWriter.Send(twitch.MakeJoinCommand(ch)); err != nil {
@@ -134,13 +200,13 @@ func (b *Bot) JoinChannels(channels ...string) error {
 	return nil
 }
 
-func (b *Bot) RequestCapability(capabilities ...twitch.Capability) error {
-	for _, capability := range capabilities {
-		if err := b.ircReadWriter.Send(twitch.MakeCapabilityRequest(capability)); err != nil {
-			return err
-		}
-	}
-	return nil
+func (b *Bot) State() state.Service {
+	return b.state
+}
+
+// Reconnect notifies when the server has asked for the bot to reconnect
+func (b *Bot) Reconnect() <-chan struct{} {
+	return b.reconnect
 }
 
 func (b *Bot) Errors() <-chan error {
@@ -0,0 +1,156 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/domain"
+	"github.com/ch629/go-irc-kafka/irc"
+	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIRC feeds messages into the bot & records everything sent
+type fakeIRC struct {
+	input chan parser.Message
+	sent  chan client.IrcMessage
+}
+
+func newFakeIRC() *fakeIRC {
+	return &fakeIRC{
+		input: make(chan parser.Message),
+		sent:  make(chan client.IrcMessage, 10),
+	}
+}
+
+func (f *fakeIRC) Input() <-chan parser.Message {
+	return f.input
+}
+
+func (f *fakeIRC) Send(messages ...client.IrcMessage) error {
+	for _, msg := range messages {
+		f.sent <- msg
+	}
+	return nil
+}
+
+func TestBot_ProcessMessages_ClearChat(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	irc := newFakeIRC()
+	cleared := make(chan domain.ChatCleared, 1)
+	handler := MessageHandler{}
+	handler.OnBan(func(domain.Ban) {
+		assert.Fail(t, "Chat clear was handled as a ban")
+	})
+	handler.OnChatCleared(func(c domain.ChatCleared) {
+		cleared <- c
+	})
+	b := New(irc, handler)
+	go b.ProcessMessages(ctx)
+
+	t.Run("Cleared", func(t *testing.T) {
+		irc.input <- parser.Message{
+			Tags:    map[string]string{"room-id": "1", "tmi-sent-ts": "1642715756806"},
+			Command: "CLEARCHAT",
+			Params:  []string{"#channel"},
+		}
+		select {
+		case c := <-cleared:
+			assert.Equal(t, "channel", c.ChannelName)
+		case err := <-b.Errors():
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			require.Fail(t, "Timed out waiting for chat cleared")
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		irc.input <- parser.Message{Command: "CLEARCHAT"}
+		select {
+		case err := <-b.Errors():
+			assert.ErrorIs(t, err, domain.ErrNoChannel)
+		case <-time.After(time.Second):
+			require.Fail(t, "Timed out waiting for error")
+		}
+	})
+}
+
+func TestBot_JoinChannels(t *testing.T) {
+	irc := newFakeIRC()
+	b := New(irc, MessageHandler{})
+	// Sending doesn't wait for the server to confirm the joins
+	require.NoError(t, b.JoinChannels("channel", "other"))
+	for _, expected := range []string{"JOIN #channel", "JOIN #other"} {
+		select {
+		case msg := <-irc.sent:
+			assert.Equal(t, expected, string(msg.Bytes()))
+		case <-time.After(time.Second):
+			require.Fail(t, "Timed out waiting for JOIN")
+		}
+	}
+	assert.False(t, b.State().IsInChannel("channel"))
+}
+
+func TestBot_JoinChannelsAndWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	irc := newFakeIRC()
+	b := New(irc, MessageHandler{})
+	b.nick.Store("bot")
+	go b.ProcessMessages(ctx)
+	go func() {
+		for range b.Errors() {
+		}
+	}()
+
+	errs := make(chan error)
+	go func() { errs <- b.JoinChannelsAndWait(ctx, "channel") }()
+	select {
+	case msg := <-irc.sent:
+		assert.Equal(t, "JOIN #channel", string(msg.Bytes()))
+	case <-time.After(time.Second):
+		require.Fail(t, "Timed out waiting for JOIN")
+	}
+	irc.input <- parser.Message{Prefix: "bot!bot@bot.tmi.twitch.tv", Command: "JOIN", Params: []string{"#channel"}}
+	assert.NoError(t, <-errs)
+	assert.True(t, b.State().IsInChannel("channel"))
+
+	// Already joined channels are skipped
+	assert.NoError(t, b.JoinChannelsAndWait(ctx, "channel"))
+	assert.Empty(t, irc.sent)
+
+	// Joins by other users don't count
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer timeoutCancel()
+	go func() { errs <- b.JoinChannelsAndWait(timeoutCtx, "other") }()
+	<-irc.sent
+	irc.input <- parser.Message{Prefix: "user!user@user.tmi.twitch.tv", Command: "JOIN", Params: []string{"#other"}}
+	assert.ErrorIs(t, <-errs, context.DeadlineExceeded)
+
+	irc.input <- parser.Message{Prefix: "bot!bot@bot.tmi.twitch.tv", Command: "PART", Params: []string{"#channel"}}
+	assert.Eventually(t, func() bool {
+		return !b.State().IsInChannel("channel")
+	}, time.Second, time.Millisecond)
+}
+
+func TestBot_ProcessMessages_Numerics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fake := newFakeIRC()
+	b := New(fake, MessageHandler{})
+	go b.ProcessMessages(ctx)
+
+	// Informational numerics are dropped, errors are reported
+	fake.input <- parser.Message{Command: string(irc.RplMOTD), Params: []string{"bot", "-"}}
+	fake.input <- parser.Message{Command: string(irc.ErrBannedFromChan), Params: []string{"bot", "#channel", "Cannot join channel (+b)"}}
+	select {
+	case err := <-b.Errors():
+		assert.ErrorIs(t, err, irc.ErrBannedFromChan)
+	case <-time.After(time.Second):
+		require.Fail(t, "Timed out waiting for error")
+	}
+}
@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/ch629/go-irc-kafka/twitch"
+)
+
+// DefaultCapabilityTimeout is how long RequestCapability waits for the server to ACK or NAK each capability
+const DefaultCapabilityTimeout = 10 * time.Second
+
+var (
+	ErrCapabilityRejected = errors.New("capability rejected")
+	ErrCapabilityTimeout  = errors.New("timed out waiting for capability response")
+)
+
+// capabilities tracks the capabilities acknowledged by the server, negotiated by the latest RequestCapability
+type capabilities struct {
+	mux        sync.Mutex
+	acked      map[twitch.Capability]struct{}
+	negotiator *irc.CapNegotiator
+	wanted     []twitch.Capability
+	// done is closed once the negotiator has finished
+	done chan struct{}
+}
+
+func newCapabilities() *capabilities {
+	return &capabilities{
+		acked: make(map[twitch.Capability]struct{}),
+	}
+}
+
+// negotiate starts a new negotiation for the capabilities, returning the command to begin it & a channel closed once it's done
+func (c *capabilities) negotiate(capabilities []twitch.Capability) (irc.CapCommand, <-chan struct{}) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.negotiator = twitch.NewCapNegotiator(capabilities...)
+	// Registration has already finished, so there's nothing for CAP END to end
+	c.negotiator.HoldEnd()
+	c.wanted = capabilities
+	c.done = make(chan struct{})
+	return c.negotiator.Begin(), c.done
+}
+
+// handle passes a CAP message to the negotiator, returning any commands to send in response
+func (c *capabilities) handle(message parser.Message) ([]irc.CapCommand, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.negotiator == nil {
+		return nil, nil
+	}
+	replies, done, err := c.negotiator.Handle(message)
+	if err != nil {
+		return nil, err
+	}
+	// ACKs, NAKs & DELs after negotiation are tracked too, capabilities from earlier negotiations are kept
+	for _, capability := range c.wanted {
+		delete(c.acked, capability)
+	}
+	for _, capability := range twitch.EnabledCapabilities(c.negotiator) {
+		c.acked[capability] = struct{}{}
+	}
+	if done {
+		select {
+		case <-c.done:
+		default:
+			close(c.done)
+		}
+	}
+	return replies, nil
+}
+
+// rejected returns the first capability which wasn't acknowledged, either from a NAK or the server not supporting it
+func (c *capabilities) rejected(capabilities []twitch.Capability) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for _, capability := range capabilities {
+		if _, ok := c.acked[capability]; !ok {
+			return fmt.Errorf("%w: %v", ErrCapabilityRejected, capability)
+		}
+	}
+	return nil
+}
+
+func (c *capabilities) has(capability twitch.Capability) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	_, ok := c.acked[capability]
+	return ok
+}
+
+func (c *capabilities) list() []twitch.Capability {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	caps := make([]twitch.Capability, 0, len(c.acked))
+	for capability := range c.acked {
+		caps = append(caps, capability)
+	}
+	return caps
+}
+
+// RequestCapability negotiates the capabilities with CAP LS & REQ, blocking until the server has ACKed or NAKed all of them
+// returns an error wrapping ErrCapabilityRejected for the first capability which was NAKed or isn't supported
+func (b *Bot) RequestCapability(ctx context.Context, capabilities ...twitch.Capability) error {
+	if len(capabilities) == 0 {
+		return nil
+	}
+	begin, done := b.capabilities.negotiate(capabilities)
+	if err := b.ircReadWriter.Send(begin); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.capabilityTimeout)
+	defer cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", ErrCapabilityTimeout, capabilities)
+	}
+	return b.capabilities.rejected(capabilities)
+}
+
+// HasCapability returns whether the server has acknowledged the capability
+func (b *Bot) HasCapability(capability twitch.Capability) bool {
+	return b.capabilities.has(capability)
+}
+
+// Capabilities is every capability the server has acknowledged
+func (b *Bot) Capabilities() []twitch.Capability {
+	return b.capabilities.list()
+}
+
+// handleCapability passes CAP messages to the negotiator, sending any REQs it needs
+// :tmi.twitch.tv CAP * ACK :twitch.tv/tags twitch.tv/commands
+func (b *Bot) handleCapability(message parser.Message) {
+	replies, err := b.capabilities.handle(message)
+	if err != nil {
+		b.error(fmt.Errorf("failed to negotiate capabilities: %w", err))
+		return
+	}
+	for _, reply := range replies {
+		if err := b.ircReadWriter.Send(reply); err != nil {
+			b.error(fmt.Errorf("failed to send %v: %w", string(reply.Bytes()), err))
+		}
+	}
+}
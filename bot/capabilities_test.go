@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/ch629/go-irc-kafka/twitch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBot_RequestCapability(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	irc := newFakeIRC()
+	b := New(irc, MessageHandler{})
+	go b.ProcessMessages(ctx)
+	go func() {
+		for range b.Errors() {
+		}
+	}()
+	// Membership isn't supported, so it's never requested
+	go func() {
+		for msg := range irc.sent {
+			line := string(msg.Bytes())
+			switch {
+			case line == "CAP LS 302":
+				irc.input <- parser.Message{Prefix: "tmi.twitch.tv", Command: "CAP", Params: []string{"*", "LS", "twitch.tv/tags twitch.tv/commands"}}
+			case strings.HasPrefix(line, "CAP REQ "):
+				caps := strings.TrimPrefix(strings.TrimPrefix(line, "CAP REQ "), ":")
+				irc.input <- parser.Message{Prefix: "tmi.twitch.tv", Command: "CAP", Params: []string{"*", "ACK", caps}}
+			}
+		}
+	}()
+
+	t.Run("Acknowledged", func(t *testing.T) {
+		assert.NoError(t, b.RequestCapability(ctx, twitch.TAGS, twitch.COMMANDS))
+		assert.True(t, b.HasCapability(twitch.TAGS))
+		assert.ElementsMatch(t, []twitch.Capability{twitch.TAGS, twitch.COMMANDS}, b.Capabilities())
+	})
+
+	t.Run("Deleted", func(t *testing.T) {
+		irc.input <- parser.Message{Prefix: "tmi.twitch.tv", Command: "CAP", Params: []string{"*", "DEL", "twitch.tv/commands"}}
+		assert.Eventually(t, func() bool {
+			return !b.HasCapability(twitch.COMMANDS)
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		err := b.RequestCapability(ctx, twitch.MEMBERSHIP, twitch.TAGS)
+		assert.ErrorIs(t, err, ErrCapabilityRejected)
+		assert.False(t, b.HasCapability(twitch.MEMBERSHIP))
+		assert.True(t, b.HasCapability(twitch.TAGS))
+	})
+
+	t.Run("Nothing requested", func(t *testing.T) {
+		assert.NoError(t, b.RequestCapability(ctx))
+	})
+}
+
+func TestBot_RequestCapability_Timeout(t *testing.T) {
+	b := New(newFakeIRC(), MessageHandler{})
+	b.capabilityTimeout = time.Millisecond
+	assert.ErrorIs(t, b.RequestCapability(context.Background(), twitch.TAGS), ErrCapabilityTimeout)
+}
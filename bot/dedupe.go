@@ -0,0 +1,37 @@
+package bot
+
+import "sync"
+
+// messageIDs remembers the most recently seen message IDs, so messages received on more than one connection are only handled once
+type messageIDs struct {
+	mux   sync.Mutex
+	ids   map[string]struct{}
+	order []string
+	next  int
+}
+
+func newMessageIDs(size int) *messageIDs {
+	return &messageIDs{
+		ids:   make(map[string]struct{}, size),
+		order: make([]string, size),
+	}
+}
+
+// Add records the ID, returning false if it has already been seen
+// Empty IDs are never recorded
+func (m *messageIDs) Add(id string) bool {
+	if len(id) == 0 {
+		return true
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.ids[id]; ok {
+		return false
+	}
+	// Evict the oldest ID to keep the set bounded
+	delete(m.ids, m.order[m.next])
+	m.order[m.next] = id
+	m.next = (m.next + 1) % len(m.order)
+	m.ids[id] = struct{}{}
+	return true
+}
@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageIDs_Add(t *testing.T) {
+	t.Run("Duplicate", func(t *testing.T) {
+		ids := newMessageIDs(2)
+		assert.True(t, ids.Add("a"))
+		assert.False(t, ids.Add("a"))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		ids := newMessageIDs(2)
+		assert.True(t, ids.Add(""))
+		assert.True(t, ids.Add(""))
+	})
+
+	t.Run("Evicts oldest", func(t *testing.T) {
+		ids := newMessageIDs(2)
+		assert.True(t, ids.Add("a"))
+		assert.True(t, ids.Add("b"))
+		assert.True(t, ids.Add("c"))
+		assert.True(t, ids.Add("a"))
+		assert.False(t, ids.Add("c"))
+	})
+}
@@ -1,16 +1,179 @@
 package bot
 
-import "github.com/ch629/go-irc-kafka/domain"
+import (
+	"errors"
+	"fmt"
+	"strings"
 
-type MessageHandler struct {
-	onPrivateMessage func(msg domain.ChatMessage)
-	onBan            func(ban domain.Ban)
+	"github.com/ch629/go-irc-kafka/domain"
+	"github.com/ch629/go-irc-kafka/irc"
+	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/ch629/go-irc-kafka/state"
+)
+
+type (
+	// HandlerFunc handles a single IRC message, any error returned is sent to Bot.Errors
+	HandlerFunc func(message parser.Message) error
+
+	// MessageHandler is a registry of handlers for each IRC command
+	MessageHandler struct {
+		handlers   map[string][]HandlerFunc
+		raw        []HandlerFunc
+		middleware []Middleware
+	}
+)
+
+// Handle registers a handler for every message with the command, multiple handlers for a command are called in the order they were registered
+func (h *MessageHandler) Handle(command string, f HandlerFunc) {
+	if h.handlers == nil {
+		h.handlers = make(map[string][]HandlerFunc)
+	}
+	h.handlers[command] = append(h.handlers[command], f)
+}
+
+// HandleRaw registers a handler for every message, regardless of the command
+func (h *MessageHandler) HandleRaw(f HandlerFunc) {
+	h.raw = append(h.raw, f)
+}
+
+// Use adds middleware around the handling of every message, middleware is called in the order it was added
+func (h *MessageHandler) Use(middleware ...Middleware) {
+	h.middleware = append(h.middleware, middleware...)
 }
 
 func (h *MessageHandler) OnPrivateMessage(f func(msg domain.ChatMessage)) {
-	h.onPrivateMessage = f
+	h.Handle(irc.PrivateMessage, func(message parser.Message) error {
+		msg, err := domain.MakeChatMessage(message)
+		if err != nil {
+			return fmt.Errorf("failed to map chat message %w", err)
+		}
+		f(*msg)
+		return nil
+	})
 }
 
 func (h *MessageHandler) OnBan(f func(ban domain.Ban)) {
-	h.onBan = f
+	h.Handle(irc.ClearChat, func(message parser.Message) error {
+		if domain.IsChatCleared(message) {
+			return nil
+		}
+		ban, err := domain.NewBan(message)
+		if err != nil {
+			return fmt.Errorf("failed to map ban message %w", err)
+		}
+		f(*ban)
+		return nil
+	})
+}
+
+// OnChatCleared handles CLEARCHATs without a user, which clear the whole chat
+func (h *MessageHandler) OnChatCleared(f func(cleared domain.ChatCleared)) {
+	h.Handle(irc.ClearChat, func(message parser.Message) error {
+		if !domain.IsChatCleared(message) {
+			return nil
+		}
+		cleared, err := domain.NewChatCleared(message)
+		if err != nil {
+			return fmt.Errorf("failed to map chat cleared message %w", err)
+		}
+		f(*cleared)
+		return nil
+	})
+}
+
+func (h *MessageHandler) OnMessageDeletion(f func(deletion domain.MessageDeletion)) {
+	h.Handle(irc.ClearMessage, func(message parser.Message) error {
+		deletion, err := domain.NewMessageDeletion(message)
+		if err != nil {
+			return fmt.Errorf("failed to map message deletion %w", err)
+		}
+		f(*deletion)
+		return nil
+	})
+}
+
+// OnRoomStateChange tracks the RoomState of every channel, handling each ROOMSTATE which changes a setting
+func (h *MessageHandler) OnRoomStateChange(f func(change domain.RoomStateChange)) {
+	roomStates := state.NewRoomStates()
+	h.Handle(irc.RoomState, func(message parser.Message) error {
+		update, err := domain.NewRoomStateUpdate(message)
+		if err != nil {
+			return fmt.Errorf("failed to map room state %w", err)
+		}
+		if change := roomStates.Update(*update); change.Changes.HasChanges() {
+			f(change)
+		}
+		return nil
+	})
+}
+
+// OnUserNotice handles subs, raids & other channel events, notices with an unsupported msg-id are skipped
+func (h *MessageHandler) OnUserNotice(f func(notice domain.UserNotice)) {
+	h.Handle(irc.UserNotice, func(message parser.Message) error {
+		notice, err := domain.NewUserNotice(message)
+		if errors.Is(err, domain.ErrUnsupportedUserNotice) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to map user notice %w", err)
+		}
+		f(*notice)
+		return nil
+	})
+}
+
+// OnMembership handles users joining & leaving channels, including the bot itself
+func (h *MessageHandler) OnMembership(f func(membership domain.Membership)) {
+	handler := func(message parser.Message) error {
+		membership, err := domain.NewMembership(message)
+		if err != nil {
+			return fmt.Errorf("failed to map membership %w", err)
+		}
+		f(*membership)
+		return nil
+	}
+	h.Handle(irc.Join, handler)
+	h.Handle(irc.Part, handler)
+}
+
+// TrackModerator keeps the channels the bot is a moderator in up to date from each USERSTATE, so the limiter can use the moderator limit
+func (h *MessageHandler) TrackModerator(limiter *client.RateLimiter) {
+	h.Handle(irc.UserState, func(message parser.Message) error {
+		if ch := message.Params.Channel(); len(ch) > 0 {
+			moderator := message.Tags["mod"] == "1" || strings.Contains(message.Tags["badges"], "broadcaster/")
+			limiter.SetModerator(ch, moderator)
+		}
+		return nil
+	})
+}
+
+// dispatch passes the message through the middleware & calls every handler registered for it
+// Returns false only when the message reached the end of the chain without any handlers
+func (h MessageHandler) dispatch(message parser.Message, onError func(error)) bool {
+	handled := true
+	handler := chain(h.middleware, func(message parser.Message) error {
+		handled = h.callHandlers(message, onError)
+		return nil
+	})
+	if err := handler(message); err != nil {
+		onError(err)
+	}
+	return handled
+}
+
+// callHandlers calls every handler registered for the message, returning whether there were any handlers
+func (h MessageHandler) callHandlers(message parser.Message, onError func(error)) bool {
+	handlers := h.handlers[message.Command]
+	for _, f := range handlers {
+		if err := f(message); err != nil {
+			onError(err)
+		}
+	}
+	for _, f := range h.raw {
+		if err := f(message); err != nil {
+			onError(err)
+		}
+	}
+	return len(handlers) > 0 || len(h.raw) > 0
 }
@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ch629/go-irc-kafka/domain"
+	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageHandler_dispatch(t *testing.T) {
+	t.Run("Multiple handlers", func(t *testing.T) {
+		var calls []string
+		h := MessageHandler{}
+		h.Handle("USERNOTICE", func(parser.Message) error {
+			calls = append(calls, "first")
+			return nil
+		})
+		h.Handle("USERNOTICE", func(parser.Message) error {
+			calls = append(calls, "second")
+			return nil
+		})
+		h.Handle("JOIN", func(parser.Message) error {
+			calls = append(calls, "join")
+			return nil
+		})
+		handled := h.dispatch(parser.Message{Command: "USERNOTICE"}, func(err error) {
+			assert.NoError(t, err)
+		})
+		assert.True(t, handled)
+		assert.Equal(t, []string{"first", "second"}, calls)
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		var commands []string
+		h := MessageHandler{}
+		h.HandleRaw(func(message parser.Message) error {
+			commands = append(commands, message.Command)
+			return nil
+		})
+		assert.True(t, h.dispatch(parser.Message{Command: "HOSTTARGET"}, func(error) {}))
+		assert.True(t, h.dispatch(parser.Message{Command: "353"}, func(error) {}))
+		assert.Equal(t, []string{"HOSTTARGET", "353"}, commands)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		expected := errors.New("handler failed")
+		h := MessageHandler{}
+		h.Handle("NOTICE", func(parser.Message) error {
+			return expected
+		})
+		var errs []error
+		h.dispatch(parser.Message{Command: "NOTICE"}, func(err error) {
+			errs = append(errs, err)
+		})
+		assert.Equal(t, []error{expected}, errs)
+	})
+
+	t.Run("Unhandled", func(t *testing.T) {
+		h := MessageHandler{}
+		assert.False(t, h.dispatch(parser.Message{Command: "NOTICE"}, func(error) {}))
+	})
+}
+
+func TestMessageHandler_Use(t *testing.T) {
+	t.Run("Order", func(t *testing.T) {
+		var calls []string
+		h := MessageHandler{}
+		h.Use(MiddlewareFunc(func(message parser.Message, next HandlerFunc) error {
+			calls = append(calls, "outer before")
+			err := next(message)
+			calls = append(calls, "outer after")
+			return err
+		}), MiddlewareFunc(func(message parser.Message, next HandlerFunc) error {
+			calls = append(calls, "inner")
+			return next(message)
+		}))
+		h.Handle("PRIVMSG", func(parser.Message) error {
+			calls = append(calls, "handler")
+			return nil
+		})
+		assert.True(t, h.dispatch(parser.Message{Command: "PRIVMSG"}, func(error) {}))
+		assert.Equal(t, []string{"outer before", "inner", "handler", "outer after"}, calls)
+	})
+
+	t.Run("Dropped", func(t *testing.T) {
+		h := MessageHandler{}
+		h.Use(MiddlewareFunc(func(parser.Message, HandlerFunc) error {
+			return nil
+		}))
+		h.Handle("PRIVMSG", func(parser.Message) error {
+			assert.Fail(t, "Dropped message was handled")
+			return nil
+		})
+		assert.True(t, h.dispatch(parser.Message{Command: "PRIVMSG"}, func(error) {}))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		expected := errors.New("middleware failed")
+		h := MessageHandler{}
+		h.Use(MiddlewareFunc(func(parser.Message, HandlerFunc) error {
+			return expected
+		}))
+		var errs []error
+		h.dispatch(parser.Message{Command: "PRIVMSG"}, func(err error) {
+			errs = append(errs, err)
+		})
+		assert.Equal(t, []error{expected}, errs)
+	})
+}
+
+func TestMessageHandler_OnMembership(t *testing.T) {
+	var memberships []domain.Membership
+	h := MessageHandler{}
+	h.OnMembership(func(membership domain.Membership) {
+		memberships = append(memberships, membership)
+	})
+	onError := func(err error) {
+		assert.NoError(t, err)
+	}
+	h.dispatch(parser.Message{Prefix: "nick!user@host", Command: "JOIN", Params: parser.Params{"#channel"}}, onError)
+	h.dispatch(parser.Message{Prefix: "nick!user@host", Command: "PART", Params: parser.Params{"#channel"}}, onError)
+
+	require.Len(t, memberships, 2)
+	assert.Equal(t, domain.MembershipJoin, memberships[0].Type)
+	assert.Equal(t, domain.MembershipPart, memberships[1].Type)
+	assert.Equal(t, parser.Source{Name: "nick", User: "user", Host: "host"}, memberships[1].Source)
+	assert.Equal(t, "channel", memberships[1].ChannelName)
+}
+
+func TestMessageHandler_TrackModerator(t *testing.T) {
+	limiter := client.NewTwitchRateLimiter(client.Limit{}, client.Limit{}, client.Limit{})
+	h := MessageHandler{}
+	h.TrackModerator(limiter)
+	onError := func(err error) {
+		assert.NoError(t, err)
+	}
+
+	h.dispatch(parser.Message{Tags: parser.Tags{"mod": "1"}, Command: "USERSTATE", Params: parser.Params{"#moderated"}}, onError)
+	h.dispatch(parser.Message{Tags: parser.Tags{"mod": "0", "badges": "broadcaster/1"}, Command: "USERSTATE", Params: parser.Params{"#owned"}}, onError)
+	h.dispatch(parser.Message{Tags: parser.Tags{"mod": "0"}, Command: "USERSTATE", Params: parser.Params{"#other"}}, onError)
+	assert.True(t, limiter.IsModerator("moderated"))
+	assert.True(t, limiter.IsModerator("owned"))
+	assert.False(t, limiter.IsModerator("other"))
+
+	// Losing moderator is tracked too
+	h.dispatch(parser.Message{Tags: parser.Tags{"mod": "0"}, Command: "USERSTATE", Params: parser.Params{"#moderated"}}, onError)
+	assert.False(t, limiter.IsModerator("moderated"))
+}
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/ch629/go-irc-kafka/twitch"
+)
+
+var (
+	ErrChannelSuspended  = errors.New("channel is suspended")
+	ErrBannedFromChannel = errors.New("banned from channel")
+	ErrJoinTimeout       = errors.New("timed out waiting for join to be confirmed")
+)
+
+// joinFailures maps the msg-id of a NOTICE to the error for a failed join
+var joinFailures = map[string]error{
+	"msg_channel_suspended": ErrChannelSuspended,
+	"msg_banned":            ErrBannedFromChannel,
+}
+
+// JoinError is a NOTICE received from the server while joining a channel
+type JoinError struct {
+	Channel string
+	MsgID   string
+	Message string
+	Err     error
+}
+
+func (e *JoinError) Error() string {
+	return fmt.Sprintf("failed to join %v: %v", e.Channel, e.Message)
+}
+
+func (e *JoinError) Unwrap() error {
+	return e.Err
+}
+
+// JoinChannelsAndWait is the blocking variant of JoinChannels, skipping channels already joined & waiting until the server has confirmed each join
+// returns the error of the first channel which failed
+func (b *Bot) JoinChannelsAndWait(ctx context.Context, channels ...string) error {
+	results := b.JoinChannelResults(ctx, channels...)
+	for _, ch := range channels {
+		if err := results[ch]; err != nil {
+			return fmt.Errorf("failed to join %v: %w", ch, err)
+		}
+	}
+	return nil
+}
+
+// JoinChannelResults joins every channel not already joined, blocking until each join has been confirmed or failed
+// Each channel is given DefaultJoinTimeout to be confirmed, returning ErrJoinTimeout if it isn't
+// Every channel is in the result, with a nil error if it was joined
+func (b *Bot) JoinChannelResults(ctx context.Context, channels ...string) map[string]error {
+	results := make(map[string]error, len(channels))
+	var (
+		mux sync.Mutex
+		wg  sync.WaitGroup
+	)
+	// Goroutines for earlier channels can still be writing results while later channels are sent
+	setResult := func(ch string, err error) {
+		mux.Lock()
+		results[ch] = err
+		mux.Unlock()
+	}
+	for _, ch := range channels {
+		if b.state.IsInChannel(ch) {
+			setResult(ch, nil)
+			continue
+		}
+		// The waiter is registered first, as a failure NOTICE can arrive before a goroutine would be waiting
+		wait := b.state.ExpectJoin(ch)
+		if err := b.ircReadWriter.Send(twitch.MakeJoinCommand(ch)); err != nil {
+			abandonJoin(wait)
+			setResult(ch, err)
+			continue
+		}
+		wg.Add(1)
+		go func(ch string) {
+			defer wg.Done()
+			setResult(ch, b.waitForJoin(ctx, wait))
+		}(ch)
+	}
+	wg.Wait()
+	return results
+}
+
+// abandonJoin removes a waiter from ExpectJoin which will never be waited on, so a later JOIN isn't delivered to it
+func abandonJoin(wait func(ctx context.Context) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = wait(ctx)
+}
+
+func (b *Bot) waitForJoin(ctx context.Context, wait func(ctx context.Context) error) error {
+	joinCtx, cancel := context.WithTimeout(ctx, b.joinTimeout)
+	defer cancel()
+	err := wait(joinCtx)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return ErrJoinTimeout
+	}
+	return err
+}
+
+// handleJoinNotice fails any pending join for the channel, returning false if the NOTICE isn't a join failure
+func (b *Bot) handleJoinNotice(message parser.Message) bool {
+	msgID := message.Tags["msg-id"]
+	err, ok := joinFailures[msgID]
+	if !ok || len(message.Params) == 0 {
+		return false
+	}
+	joinErr := &JoinError{
+		Channel: message.Params.Channel(),
+		MsgID:   msgID,
+		Err:     err,
+	}
+	if len(message.Params) > 1 {
+		joinErr.Message = message.Params[1]
+	}
+	b.state.FailJoin(joinErr.Channel, joinErr)
+	return true
+}
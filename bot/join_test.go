@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBot_JoinChannelResults(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	irc := newFakeIRC()
+	b := New(irc, MessageHandler{})
+	b.nick.Store("bot")
+	go b.ProcessMessages(ctx)
+	go func() {
+		for range b.Errors() {
+		}
+	}()
+	// Reply to each JOIN, either confirming it or failing it
+	go func() {
+		for msg := range irc.sent {
+			switch string(msg.Bytes()) {
+			case "JOIN #joined":
+				irc.input <- parser.Message{Prefix: "bot!bot@bot.tmi.twitch.tv", Command: "JOIN", Params: []string{"#joined"}}
+			case "JOIN #roomstate":
+				irc.input <- parser.Message{Tags: map[string]string{"room-id": "1"}, Command: "ROOMSTATE", Params: []string{"#roomstate"}}
+			case "JOIN #suspended":
+				irc.input <- parser.Message{
+					Tags:    map[string]string{"msg-id": "msg_channel_suspended"},
+					Command: "NOTICE",
+					Params:  []string{"#suspended", "This channel has been suspended."},
+				}
+			}
+		}
+	}()
+
+	results := b.JoinChannelResults(ctx, "joined", "roomstate", "suspended")
+	assert.Len(t, results, 3)
+	assert.NoError(t, results["joined"])
+	assert.NoError(t, results["roomstate"])
+	assert.ErrorIs(t, results["suspended"], ErrChannelSuspended)
+	var joinErr *JoinError
+	if assert.ErrorAs(t, results["suspended"], &joinErr) {
+		assert.Equal(t, "This channel has been suspended.", joinErr.Message)
+	}
+
+	err := b.JoinChannelsAndWait(ctx, "joined", "suspended")
+	assert.ErrorIs(t, err, ErrChannelSuspended)
+}
+
+func TestBot_waitForJoin_Timeout(t *testing.T) {
+	b := New(newFakeIRC(), MessageHandler{})
+	b.joinTimeout = time.Millisecond
+	assert.ErrorIs(t, b.waitForJoin(context.Background(), b.state.ExpectJoin("channel")), ErrJoinTimeout)
+
+	// The parent context expiring is returned as is
+	b.joinTimeout = time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.waitForJoin(ctx, b.state.ExpectJoin("channel")), context.DeadlineExceeded)
+}
+
+// failingIRC fails to send JOINs for one channel, taking a while as if it was throttled
+type failingIRC struct {
+	*fakeIRC
+	fail string
+}
+
+var errSendFailed = errors.New("send failed")
+
+func (f *failingIRC) Send(messages ...client.IrcMessage) error {
+	for _, msg := range messages {
+		if string(msg.Bytes()) == "JOIN #"+f.fail {
+			time.Sleep(10 * time.Millisecond)
+			return errSendFailed
+		}
+	}
+	return f.fakeIRC.Send(messages...)
+}
+
+func TestBot_JoinChannelResults_SendFailed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	irc := &failingIRC{fakeIRC: newFakeIRC(), fail: "broken"}
+	b := New(irc, MessageHandler{})
+	b.nick.Store("bot")
+	go b.ProcessMessages(ctx)
+	go func() {
+		for range b.Errors() {
+		}
+	}()
+	go func() {
+		for msg := range irc.sent {
+			if ch := strings.TrimPrefix(string(msg.Bytes()), "JOIN "); ch != string(msg.Bytes()) {
+				irc.input <- parser.Message{Prefix: "bot!bot@bot.tmi.twitch.tv", Command: "JOIN", Params: []string{ch}}
+			}
+		}
+	}()
+
+	// The first channel is confirmed while the second is still being sent
+	results := b.JoinChannelResults(ctx, "joined", "broken", "other")
+	assert.NoError(t, results["joined"])
+	assert.ErrorIs(t, results["broken"], errSendFailed)
+	assert.NoError(t, results["other"])
+	assert.False(t, b.State().IsInChannel("broken"))
+}
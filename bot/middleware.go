@@ -0,0 +1,29 @@
+package bot
+
+import "github.com/ch629/go-irc-kafka/irc/parser"
+
+type (
+	// Middleware wraps the handling of each message, calling next to continue down the chain
+	// Not calling next drops the message
+	Middleware interface {
+		Handle(message parser.Message, next HandlerFunc) error
+	}
+
+	// MiddlewareFunc allows a plain function to be used as Middleware
+	MiddlewareFunc func(message parser.Message, next HandlerFunc) error
+)
+
+func (f MiddlewareFunc) Handle(message parser.Message, next HandlerFunc) error {
+	return f(message, next)
+}
+
+// chain wraps the handler in the middleware, the first middleware being the outermost
+func chain(middleware []Middleware, handler HandlerFunc) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		mw, next := middleware[i], handler
+		handler = func(message parser.Message) error {
+			return mw.Handle(message, next)
+		}
+	}
+	return handler
+}
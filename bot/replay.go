@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/ch629/go-irc-kafka/irc/client"
+)
+
+// Replay feeds a recording through a Bot as if it was read from IRC, blocking until the recording ends or the context is cancelled
+// speed is how many times faster than recorded to replay, 0 replays as fast as possible
+// onError may be called from multiple goroutines
+func Replay(ctx context.Context, recording io.Reader, speed float64, messageHandler MessageHandler, onError func(error)) error {
+	conn := client.NewReplayConn(recording, speed, onError)
+	// Errors block until they're reported, and messages without a server-time keep the time they were recorded
+	cli := client.NewClient(ctx, conn, client.WithBlockingErrors(), client.WithReadTime(conn.ReadTime))
+	go cli.ConsumeMessages()
+
+	b := New(cli, messageHandler)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for err := range b.Errors() {
+			onError(err)
+		}
+	}()
+	// Invalid records & lines are skipped, but still reported
+	go func() {
+		defer wg.Done()
+		for err := range cli.Errors() {
+			onError(err)
+		}
+	}()
+	// Returns once the client has closed the input at the end of the recording
+	b.ProcessMessages(ctx)
+	_ = cli.Close()
+	// onError isn't called once Replay returns
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := cli.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
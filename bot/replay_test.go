@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/domain"
+	"github.com/ch629/go-irc-kafka/irc"
+	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	recording := `2022-01-20T21:55:56Z ":tmi.twitch.tv PING :tmi.twitch.tv\r\n:nick!user@host JOIN #channel\r\n"
+not a record
+2022-01-20T21:55:56.01Z ":nick!user@host PART #channel\r\n:tmi.twitch.tv 421 nick FOO :Unknown command\r\n:tmi.twitch.tv\r\n"
+`
+	var memberships []domain.MembershipType
+	var times []time.Time
+	h := MessageHandler{}
+	h.OnMembership(func(membership domain.Membership) {
+		memberships = append(memberships, membership.Type)
+		times = append(times, membership.Time)
+	})
+	var errsMux sync.Mutex
+	var errs []error
+	err := Replay(context.Background(), strings.NewReader(recording), 1, h, func(err error) {
+		errsMux.Lock()
+		defer errsMux.Unlock()
+		errs = append(errs, err)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.MembershipType{domain.MembershipJoin, domain.MembershipPart}, memberships)
+	// JOIN & PART have no server-time, so they keep the time they were recorded
+	assert.Equal(t, []time.Time{
+		time.Date(2022, 1, 20, 21, 55, 56, 0, time.UTC),
+		time.Date(2022, 1, 20, 21, 55, 56, 10*int(time.Millisecond), time.UTC),
+	}, times)
+	// The invalid record, unknown command & line without a command are all reported
+	require.Len(t, errs, 3)
+	var found []bool
+	for _, target := range []error{client.ErrInvalidRecord, irc.ErrUnknownCommand, parser.ErrNoCommand} {
+		found = append(found, containsError(errs, target))
+	}
+	assert.Equal(t, []bool{true, true, true}, found)
+}
+
+func containsError(errs []error, target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ch629/go-irc-kafka/irc"
+	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/ch629/go-irc-kafka/twitch"
+)
+
+// saslCapability is the IRCv3 capability advertising SASL support
+const saslCapability = "sasl"
+
+// negotiation receives the messages needed by LoginSASL while it's running
+type negotiation struct {
+	messages chan parser.Message
+	done     chan struct{}
+}
+
+// LoginSASL registers with a generic IRC server, authenticating with SASL during capability negotiation
+// Any other capabilities are requested alongside sasl, blocking until registration has finished, fails or the context is cancelled
+func (b *Bot) LoginSASL(ctx context.Context, nick, user string, mechanism irc.SASLMechanism, capabilities ...string) error {
+	b.loginMux.Lock()
+	defer b.loginMux.Unlock()
+	n := &negotiation{
+		messages: make(chan parser.Message),
+		done:     make(chan struct{}),
+	}
+	b.setNegotiation(n)
+	defer func() {
+		b.setNegotiation(nil)
+		close(n.done)
+	}()
+	b.nick.Store(nick)
+
+	negotiator := irc.NewCapNegotiator(append(capabilities, saslCapability)...)
+	// CAP END finishes registration, so has to wait until we've authenticated
+	negotiator.HoldEnd()
+	if err := b.ircReadWriter.Send(negotiator.Begin(), twitch.MakeNickCommand(nick), irc.UserCommand{User: user}); err != nil {
+		return err
+	}
+	authenticating := false
+	for {
+		var message parser.Message
+		select {
+		case message = <-n.messages:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		switch message.Command {
+		case irc.Capability:
+			replies, done, err := negotiator.Handle(message)
+			if err != nil {
+				return err
+			}
+			if err := b.ircReadWriter.Send(capCommands(replies)...); err != nil {
+				return err
+			}
+			if !done || authenticating {
+				continue
+			}
+			sasl, ok := negotiator.Enabled(saslCapability)
+			if !ok {
+				return irc.ErrSASLUnavailable
+			}
+			if !irc.SupportsMechanism(sasl, mechanism) {
+				return fmt.Errorf("%w: %v", irc.ErrSASLMechanismUnsupported, mechanism.Name())
+			}
+			authenticating = true
+			if err := b.ircReadWriter.Send(irc.AuthenticateCommand{Param: mechanism.Name()}); err != nil {
+				return err
+			}
+		case irc.Authenticate:
+			// The server is ready for our response
+			if len(message.Params) == 0 || message.Params[0] != "+" {
+				continue
+			}
+			if err := b.ircReadWriter.Send(authenticateCommands(irc.SASLResponse(mechanism))...); err != nil {
+				return err
+			}
+		case string(irc.RplSASLSuccess):
+			if err := b.ircReadWriter.Send(negotiator.End()); err != nil {
+				return err
+			}
+		case string(irc.RplWelcome):
+			return nil
+		default:
+			if err := irc.NumericErr(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (b *Bot) setNegotiation(n *negotiation) {
+	b.negotiationMux.Lock()
+	defer b.negotiationMux.Unlock()
+	b.negotiation = n
+}
+
+// forwardNegotiation passes the message to LoginSASL, returning false if it isn't running or doesn't need the message
+func (b *Bot) forwardNegotiation(message parser.Message) bool {
+	if !negotiationCommand(message.Command) {
+		return false
+	}
+	b.negotiationMux.Lock()
+	n := b.negotiation
+	b.negotiationMux.Unlock()
+	if n == nil {
+		return false
+	}
+	select {
+	case n.messages <- message:
+	case <-n.done:
+	}
+	return true
+}
+
+// negotiationCommand returns whether LoginSASL needs the command
+// Any error numeric, such as ERR_SASLFAIL or ERR_NICKNAMEINUSE, fails registration
+func negotiationCommand(command string) bool {
+	switch command {
+	case irc.Capability, irc.Authenticate, string(irc.RplWelcome), string(irc.RplSASLSuccess):
+		return true
+	}
+	return irc.Numeric(command).IsError()
+}
+
+func capCommands(commands []irc.CapCommand) []client.IrcMessage {
+	messages := make([]client.IrcMessage, len(commands))
+	for i, c := range commands {
+		messages[i] = c
+	}
+	return messages
+}
+
+func authenticateCommands(commands []irc.AuthenticateCommand) []client.IrcMessage {
+	messages := make([]client.IrcMessage, len(commands))
+	for i, c := range commands {
+		messages[i] = c
+	}
+	return messages
+}
@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saslServer replies to the registration commands, accepting only the given payload
+func saslServer(fake *fakeIRC, mechanisms, payload string) <-chan string {
+	received := make(chan string, 20)
+	reply := func(command string, params ...string) {
+		fake.input <- parser.Message{Prefix: "irc.example.com", Command: command, Params: params}
+	}
+	go func() {
+		for msg := range fake.sent {
+			line := string(msg.Bytes())
+			received <- line
+			switch {
+			case line == "CAP LS 302":
+				reply(irc.Capability, "*", "LS", "multi-prefix sasl="+mechanisms)
+			case strings.HasPrefix(line, "CAP REQ :"):
+				reply(irc.Capability, "*", "ACK", strings.TrimPrefix(line, "CAP REQ :"))
+			case line == "CAP END":
+				reply(string(irc.RplWelcome), "bot", "Welcome")
+			case strings.HasPrefix(line, "AUTHENTICATE "):
+				param := strings.TrimPrefix(line, "AUTHENTICATE ")
+				switch param {
+				case "PLAIN", "EXTERNAL":
+					reply(irc.Authenticate, "+")
+				case payload:
+					reply(string(irc.RplLoggedIn), "bot", "bot!bot@host", "bot", "You are now logged in as bot")
+					reply(string(irc.RplSASLSuccess), "bot", "SASL authentication successful")
+				default:
+					reply(string(irc.ErrSASLFail), "bot", "SASL authentication failed")
+				}
+			}
+		}
+	}()
+	return received
+}
+
+func TestBot_LoginSASL(t *testing.T) {
+	run := func(t *testing.T, mechanisms string, mechanism irc.SASLMechanism) (<-chan string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		t.Cleanup(cancel)
+		fake := newFakeIRC()
+		b := New(fake, MessageHandler{})
+		go b.ProcessMessages(ctx)
+		go func() {
+			for range b.Errors() {
+			}
+		}()
+		received := saslServer(fake, mechanisms, "AGJvdABodW50ZXIy")
+		return received, b.LoginSASL(ctx, "bot", "bot", mechanism, "multi-prefix")
+	}
+
+	t.Run("Authenticated", func(t *testing.T) {
+		received, err := run(t, "PLAIN", irc.SASLPlain{Username: "bot", Password: "hunter2"})
+		require.NoError(t, err)
+		var lines []string
+		for i := 0; i < 7; i++ {
+			lines = append(lines, <-received)
+		}
+		assert.Equal(t, []string{
+			"CAP LS 302",
+			"NICK bot",
+			"USER bot 0 * bot",
+			"CAP REQ :multi-prefix sasl",
+			"AUTHENTICATE PLAIN",
+			"AUTHENTICATE AGJvdABodW50ZXIy",
+			"CAP END",
+		}, lines)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		_, err := run(t, "PLAIN", irc.SASLPlain{Username: "bot", Password: "wrong"})
+		assert.ErrorIs(t, err, irc.ErrSASLFail)
+	})
+
+	t.Run("Unsupported mechanism", func(t *testing.T) {
+		_, err := run(t, "EXTERNAL", irc.SASLPlain{Username: "bot", Password: "hunter2"})
+		assert.ErrorIs(t, err, irc.ErrSASLMechanismUnsupported)
+	})
+}
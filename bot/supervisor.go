@@ -0,0 +1,206 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc"
+	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/twitch"
+	"go.uber.org/zap"
+)
+
+// seenMessageIDs is how many message IDs are remembered for de-duplicating during a handover
+const seenMessageIDs = 4096
+
+type (
+	// Supervisor keeps a Bot connected to IRC, redialling with a backoff whenever the connection drops
+	Supervisor struct {
+		dial           client.Dialer
+		messageHandler MessageHandler
+		conf           SupervisorConfig
+		errors         chan error
+		logger         *zap.Logger
+		wg             sync.WaitGroup
+		// seen is shared between connections so messages received during a handover are only handled once
+		seen *messageIDs
+	}
+
+	// connection is a single IRC connection & the Bot processing it
+	connection struct {
+		client client.IrcClient
+		bot    *Bot
+	}
+
+	// SupervisorConfig is everything needed to bring a fresh connection back to a usable state
+	SupervisorConfig struct {
+		Name         string
+		OAuth        string
+		Capabilities []twitch.Capability
+		Channels     []string
+		Backoff      Backoff
+		// SASL logs into a generic IRC server instead of Twitch, the Capabilities aren't requested when it's set
+		SASL irc.SASLMechanism
+		// ClientOptions are applied to every client, so any RateLimiter is shared across reconnects
+		ClientOptions []client.Option
+	}
+)
+
+func NewSupervisor(dial client.Dialer, messageHandler MessageHandler, conf SupervisorConfig) *Supervisor {
+	return &Supervisor{
+		dial:           dial,
+		messageHandler: messageHandler,
+		conf:           conf,
+		errors:         make(chan error),
+		logger:         zap.L(),
+		seen:           newMessageIDs(seenMessageIDs),
+	}
+}
+
+// Run connects to IRC and reconnects whenever the connection is lost, blocking until either the context is cancelled or the login is rejected
+func (s *Supervisor) Run(ctx context.Context) error {
+	defer func() {
+		s.wg.Wait()
+		close(s.errors)
+	}()
+	for attempt := 0; ; attempt++ {
+		conn, err := s.connect(ctx)
+		if err == nil {
+			s.logger.Info("connected to irc")
+			connected := time.Now()
+			err = s.serve(ctx, conn)
+			// A connection which drops straight after logging in is still failing, so keep backing off
+			if time.Since(connected) >= s.conf.Backoff.resetAfter() {
+				attempt = 0
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Retrying won't fix bad credentials
+		if errors.Is(err, ErrBadPassword) || errors.Is(err, irc.ErrSASLFail) {
+			return err
+		}
+		wait := s.conf.Backoff.Duration(attempt)
+		s.logger.Warn("irc connection lost, reconnecting", zap.Error(err), zap.Duration("backoff", wait))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Errors from every connection the Supervisor has made, closed once Run returns
+func (s *Supervisor) Errors() <-chan error {
+	return s.errors
+}
+
+// serve blocks until the connection closes, handing over to a fresh connection whenever the server asks us to reconnect
+func (s *Supervisor) serve(ctx context.Context, conn *connection) error {
+	for {
+		select {
+		case <-conn.client.Done():
+			return conn.client.Err()
+		case <-conn.bot.Reconnect():
+			s.logger.Info("server requested reconnect, handing over to a new connection")
+			next, err := s.connect(ctx)
+			if err != nil {
+				// Keep using the old connection until the server closes it
+				s.logger.Warn("failed to open handover connection", zap.Error(err))
+				continue
+			}
+			_ = conn.client.Close()
+			conn = next
+		}
+	}
+}
+
+// connect dials a new connection & logs in, requests capabilities and joins every channel on it
+func (s *Supervisor) connect(ctx context.Context) (*connection, error) {
+	rwc, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cli := client.NewClient(ctx, rwc, s.conf.ClientOptions...)
+	go cli.ConsumeMessages()
+
+	b := New(cli, s.messageHandler)
+	b.seen = s.seen
+	s.forwardErrors(b)
+	go b.ProcessMessages(ctx)
+
+	if err := s.setup(ctx, cli, b); err != nil {
+		_ = cli.Close()
+		return nil, err
+	}
+	return &connection{client: cli, bot: b}, nil
+}
+
+// setup brings the Bot up to a usable state, giving up if the connection closes part way through
+func (s *Supervisor) setup(ctx context.Context, cli client.IrcClient, b *Bot) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-cli.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := s.login(ctx, b); err != nil {
+		return err
+	}
+	// A channel failing to join shouldn't stop the rest being archived
+	for ch, err := range b.JoinChannelResults(ctx, s.conf.Channels...) {
+		if err != nil {
+			s.logger.Warn("failed to join channel", zap.String("channel", ch), zap.Error(err))
+		}
+	}
+	return ctx.Err()
+}
+
+// login uses SASL when it's configured, otherwise Twitch's OAuth & capabilities
+func (s *Supervisor) login(ctx context.Context, b *Bot) error {
+	if s.conf.SASL != nil {
+		if err := b.LoginSASL(ctx, s.conf.Name, s.conf.Name, s.conf.SASL); err != nil {
+			return fmt.Errorf("failed to login: %w", err)
+		}
+		return nil
+	}
+	if err := b.Login(ctx, s.conf.Name, s.conf.OAuth); err != nil {
+		return fmt.Errorf("failed to login: %w", err)
+	}
+	if err := b.RequestCapability(ctx, s.conf.Capabilities...); err != nil {
+		// Chat messages can't be mapped without tags, but the other capabilities aren't required
+		if !errors.Is(err, ErrCapabilityRejected) || (requested(s.conf.Capabilities, twitch.TAGS) && !b.HasCapability(twitch.TAGS)) {
+			return fmt.Errorf("failed to request capabilities: %w", err)
+		}
+		s.logger.Warn("capability rejected", zap.Error(err))
+	}
+	return nil
+}
+
+// forwardErrors pipes the errors from a single Bot into the Supervisor errors
+func (s *Supervisor) forwardErrors(b *Bot) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for err := range b.Errors() {
+			s.errors <- err
+		}
+	}()
+}
+
+func requested(capabilities []twitch.Capability, capability twitch.Capability) bool {
+	for _, c := range capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
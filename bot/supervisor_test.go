@@ -0,0 +1,271 @@
+package bot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer accepts piped connections & replies to logins, recording every line received
+type fakeServer struct {
+	mux   sync.Mutex
+	lines []string
+	conns []net.Conn
+	joins chan string
+	// closed receives the index of each connection once the client has closed it
+	closed chan int
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{
+		joins:  make(chan string, 10),
+		closed: make(chan int, 10),
+	}
+}
+
+func (f *fakeServer) dial(context.Context) (io.ReadWriteCloser, error) {
+	clientConn, serverConn := net.Pipe()
+	f.mux.Lock()
+	f.conns = append(f.conns, serverConn)
+	i := len(f.conns) - 1
+	f.mux.Unlock()
+	go f.serve(i, serverConn)
+	return clientConn, nil
+}
+
+func (f *fakeServer) serve(i int, conn net.Conn) {
+	defer func() { f.closed <- i }()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		f.mux.Lock()
+		f.lines = append(f.lines, line)
+		f.mux.Unlock()
+		switch {
+		case strings.HasPrefix(line, "NICK"):
+			_, _ = io.WriteString(conn, ":tmi.twitch.tv 376 bot :>\r\n")
+		case strings.HasPrefix(line, "JOIN"):
+			_, _ = io.WriteString(conn, ":bot!bot@bot.tmi.twitch.tv "+line+"\r\n")
+			f.joins <- line
+		}
+	}
+}
+
+func (f *fakeServer) conn(i int) net.Conn {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.conns[i]
+}
+
+func TestSupervisor_Reconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := newFakeServer()
+	sup := NewSupervisor(server.dial, MessageHandler{}, SupervisorConfig{
+		Name:     "bot",
+		OAuth:    "token",
+		Channels: []string{"channel"},
+		Backoff:  Backoff{Min: time.Millisecond, Max: time.Millisecond},
+	})
+	go func() {
+		for range sup.Errors() {
+		}
+	}()
+	runErr := make(chan error)
+	go func() { runErr <- sup.Run(ctx) }()
+
+	waitForJoin(t, server, "JOIN #channel")
+	// Drop the first connection, the supervisor should redial and rejoin
+	require.NoError(t, server.conn(0).Close())
+	waitForJoin(t, server, "JOIN #channel")
+
+	cancel()
+	select {
+	case err := <-runErr:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "Supervisor didn't stop after cancel")
+	}
+}
+
+func TestSupervisor_Handover(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := newFakeServer()
+	messages := make(chan domain.ChatMessage, 10)
+	handler := MessageHandler{}
+	handler.OnPrivateMessage(func(msg domain.ChatMessage) {
+		messages <- msg
+	})
+	sup := NewSupervisor(server.dial, handler, SupervisorConfig{
+		Name:     "bot",
+		Channels: []string{"channel"},
+	})
+	go func() {
+		for range sup.Errors() {
+		}
+	}()
+	go func() { _ = sup.Run(ctx) }()
+
+	privmsg := "@badges=;display-name=user;id=885196de-cb67-427a-baa8-82f9b0fcd05f;mod=0;room-id=1;tmi-sent-ts=1642715756806;user-id=2 :user!user@user.tmi.twitch.tv PRIVMSG #channel :hello\r\n"
+	waitForJoin(t, server, "JOIN #channel")
+	_, err := io.WriteString(server.conn(0), privmsg+":tmi.twitch.tv RECONNECT\r\n")
+	require.NoError(t, err)
+	waitForJoin(t, server, "JOIN #channel")
+
+	// The old connection is closed once the new one is ready
+	select {
+	case i := <-server.closed:
+		assert.Equal(t, 0, i)
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "Old connection wasn't closed after handover")
+	}
+
+	// The same message arriving on the new connection is dropped
+	_, err = io.WriteString(server.conn(1), privmsg)
+	require.NoError(t, err)
+	select {
+	case msg := <-messages:
+		assert.Equal(t, "hello", msg.Message)
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "Timed out waiting for message")
+	}
+	select {
+	case <-messages:
+		assert.Fail(t, "Duplicate message was handled")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSupervisor_BadPassword(t *testing.T) {
+	sup := NewSupervisor(func(context.Context) (io.ReadWriteCloser, error) {
+		clientConn, serverConn := net.Pipe()
+		go func() {
+			_, _ = bufio.NewReader(serverConn).ReadString('\n')
+			_, _ = io.WriteString(serverConn, ":tmi.twitch.tv NOTICE * :Login authentication failed\r\n:tmi.twitch.tv 464 * :Password incorrect\r\n")
+			_, _ = io.Copy(io.Discard, serverConn)
+		}()
+		return clientConn, nil
+	}, MessageHandler{}, SupervisorConfig{})
+	go func() {
+		for range sup.Errors() {
+		}
+	}()
+
+	select {
+	case err := <-runAsync(sup):
+		assert.ErrorIs(t, err, ErrBadPassword)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "Supervisor didn't stop after a bad password")
+	}
+}
+
+func runAsync(sup *Supervisor) <-chan error {
+	errs := make(chan error, 1)
+	go func() { errs <- sup.Run(context.Background()) }()
+	return errs
+}
+
+func waitForJoin(t *testing.T, server *fakeServer, expected string) {
+	t.Helper()
+	select {
+	case join := <-server.joins:
+		assert.Equal(t, expected, join)
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "Timed out waiting for join")
+	}
+}
+
+func TestSupervisor_ReconnectsAfterReadError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := newFakeServer()
+	clientConns := make(chan net.Conn, 2)
+	dial := func(ctx context.Context) (io.ReadWriteCloser, error) {
+		conn, err := server.dial(ctx)
+		clientConns <- conn.(net.Conn)
+		return conn, err
+	}
+	sup := NewSupervisor(dial, MessageHandler{}, SupervisorConfig{
+		Name:     "bot",
+		OAuth:    "token",
+		Channels: []string{"channel"},
+		Backoff:  Backoff{Min: time.Millisecond, Max: time.Millisecond},
+	})
+	go func() {
+		for range sup.Errors() {
+		}
+	}()
+	go func() { _ = sup.Run(ctx) }()
+
+	waitForJoin(t, server, "JOIN #channel")
+	// A timeout is returned by every read without closing the connection, like a reset socket
+	require.NoError(t, (<-clientConns).SetReadDeadline(time.Now()))
+	waitForJoin(t, server, "JOIN #channel")
+}
+
+func TestSupervisor_BacksOffWhenConnectionsDrop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mux sync.Mutex
+	var dials []time.Time
+	enough := make(chan struct{})
+	// Every connection logs in & joins, then drops shortly after
+	dial := func(context.Context) (io.ReadWriteCloser, error) {
+		mux.Lock()
+		dials = append(dials, time.Now())
+		if len(dials) == 5 {
+			close(enough)
+		}
+		mux.Unlock()
+		clientConn, serverConn := net.Pipe()
+		go func() {
+			defer serverConn.Close()
+			scanner := bufio.NewScanner(serverConn)
+			for scanner.Scan() {
+				line := strings.TrimSuffix(scanner.Text(), "\r")
+				switch {
+				case strings.HasPrefix(line, "NICK"):
+					_, _ = io.WriteString(serverConn, ":tmi.twitch.tv 376 bot :>\r\n")
+				case strings.HasPrefix(line, "JOIN"):
+					_, _ = io.WriteString(serverConn, ":bot!bot@bot.tmi.twitch.tv "+line+"\r\n")
+					// Leave time for the join to be confirmed, so the connection is set up before it drops
+					time.Sleep(10 * time.Millisecond)
+					return
+				}
+			}
+		}()
+		return clientConn, nil
+	}
+	sup := NewSupervisor(dial, MessageHandler{}, SupervisorConfig{
+		Name:     "bot",
+		OAuth:    "token",
+		Channels: []string{"channel"},
+		Backoff:  Backoff{Min: 5 * time.Millisecond, Max: time.Second, Factor: 2},
+	})
+	go func() {
+		for range sup.Errors() {
+		}
+	}()
+	go func() { _ = sup.Run(ctx) }()
+
+	select {
+	case <-enough:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "Supervisor didn't redial")
+	}
+	cancel()
+	mux.Lock()
+	defer mux.Unlock()
+	// The waits are 5ms, 10ms, 20ms then 40ms, rather than starting again from 5ms after every login
+	assert.GreaterOrEqual(t, dials[4].Sub(dials[3]), 40*time.Millisecond)
+}
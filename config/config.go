@@ -3,6 +3,7 @@ package config
 import (
 	"io"
 	"sync"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
@@ -25,8 +26,84 @@ type (
 		Topic   string
 	}
 	Irc struct {
+		// Address is host:port for TCP, or a ws:// or wss:// URL for WebSocket
+		// Twitch's address for the Transport & TLS setting is used when it's empty
 		Address string
+		// Transport is either "tcp" or "websocket", Twitch listens for WebSockets on wss://irc-ws.chat.twitch.tv:443
+		Transport string
+		Reconnect Reconnect
+		TLS       TLS
+		RateLimit RateLimit
+		KeepAlive KeepAlive
+		SASL      SASL
+		Record    Record
+		Replay    Replay
 	}
+	// Record captures the raw bytes read from IRC to a file, rotating it once it reaches MaxSize bytes
+	Record struct {
+		// Path is the file to record to, recording is disabled when it's empty
+		Path     string
+		MaxSize  int64
+		MaxFiles int
+	}
+	// Replay reads a recording instead of connecting to IRC, the messages are still handled & produced to Kafka
+	Replay struct {
+		// Path is the recording to replay, IRC is dialled as normal when it's empty
+		Path string
+		// Speed is how many times faster than recorded to replay, 0 replays as fast as possible
+		Speed float64
+	}
+	// Reconnect controls the backoff between attempts when the IRC connection drops
+	Reconnect struct {
+		MinBackoff time.Duration
+		MaxBackoff time.Duration
+	}
+	// RateLimit throttles outbound messages to stay within Twitch's limits, verified bots can raise these
+	RateLimit struct {
+		Messages    int
+		MessagesPer time.Duration
+		// ModeratorMessages limits PRIVMSGs in channels where the bot is a moderator or broadcaster
+		ModeratorMessages    int
+		ModeratorMessagesPer time.Duration
+		Joins                int
+		JoinsPer             time.Duration
+		// QueueLogInterval is how often the amount of messages waiting to be sent is logged, 0 disables it
+		QueueLogInterval time.Duration
+	}
+	// KeepAlive sends a PING every Interval & drops the connection if nothing is read within the Timeout
+	KeepAlive struct {
+		Interval time.Duration
+		Timeout  time.Duration
+	}
+	// SASL authenticates with a generic IRC server instead of Twitch's OAuth, EXTERNAL uses the TLS client certificate
+	SASL struct {
+		// Mechanism is either "PLAIN" or "EXTERNAL", leaving it empty logs in with the Bot OAuth
+		Mechanism string
+		Username  string
+		Password  string
+	}
+	// TLS enables dialling IRC over TLS, Twitch listens for TLS on irc.chat.twitch.tv:6697
+	TLS struct {
+		Enabled bool
+		// CAFile is a PEM bundle of CAs to trust instead of the system roots
+		CAFile string
+		// CertFile & KeyFile are an optional client certificate
+		CertFile string
+		KeyFile  string
+	}
+)
+
+const (
+	TransportTCP       = "tcp"
+	TransportWebSocket = "websocket"
+
+	SASLPlain    = "PLAIN"
+	SASLExternal = "EXTERNAL"
+
+	// Twitch's addresses, TLS is on a separate port for TCP
+	TwitchAddress          = "irc.chat.twitch.tv:6667"
+	TwitchTLSAddress       = "irc.chat.twitch.tv:6697"
+	TwitchWebSocketAddress = "wss://irc-ws.chat.twitch.tv:443"
 )
 
 var (
@@ -41,7 +118,32 @@ var (
 			Topic:   "",
 		},
 		Irc: Irc{
-			Address: "irc.chat.twitch.tv:6667",
+			Address:   "",
+			Transport: TransportTCP,
+			Reconnect: Reconnect{
+				MinBackoff: time.Second,
+				MaxBackoff: 2 * time.Minute,
+			},
+			RateLimit: RateLimit{
+				Messages:             20,
+				MessagesPer:          30 * time.Second,
+				ModeratorMessages:    100,
+				ModeratorMessagesPer: 30 * time.Second,
+				Joins:                20,
+				JoinsPer:             10 * time.Second,
+				QueueLogInterval:     time.Minute,
+			},
+			KeepAlive: KeepAlive{
+				Interval: time.Minute,
+				Timeout:  2 * time.Minute,
+			},
+			Record: Record{
+				MaxSize:  100 << 20,
+				MaxFiles: 5,
+			},
+			Replay: Replay{
+				Speed: 1,
+			},
 		},
 	}
 
@@ -70,8 +172,27 @@ func LoadConfig(fs afero.Fs) (Config, error) {
 				return
 			}
 		}
-		err = viper.Unmarshal(&config)
+		if err = viper.Unmarshal(&config); err != nil {
+			return
+		}
+		config.Irc.Address = config.Irc.DefaultAddress()
 	})
 
 	return config, err
 }
+
+// DefaultAddress returns the Address, or Twitch's address for the Transport if it's empty
+// TLS over TCP uses a different port, so it can't just be enabled on the plain text address
+func (i Irc) DefaultAddress() string {
+	if len(i.Address) > 0 {
+		return i.Address
+	}
+	switch {
+	case i.Transport == TransportWebSocket:
+		return TwitchWebSocketAddress
+	case i.TLS.Enabled:
+		return TwitchTLSAddress
+	default:
+		return TwitchAddress
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIrc_DefaultAddress(t *testing.T) {
+	tests := map[string]struct {
+		irc      Irc
+		expected string
+	}{
+		"Plain text": {Irc{Transport: TransportTCP}, TwitchAddress},
+		"TLS":        {Irc{Transport: TransportTCP, TLS: TLS{Enabled: true}}, TwitchTLSAddress},
+		"WebSocket":  {Irc{Transport: TransportWebSocket, TLS: TLS{Enabled: true}}, TwitchWebSocketAddress},
+		"Configured": {Irc{Address: "irc.example.com:6697", TLS: TLS{Enabled: true}}, "irc.example.com:6697"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.irc.DefaultAddress())
+		})
+	}
+}
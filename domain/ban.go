@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -20,8 +21,56 @@ type Ban struct {
 	UserName        string         `json:"user_name,omitempty"`
 }
 
+// ChatCleared is a CLEARCHAT without a user, removing every message in the channel
+type ChatCleared struct {
+	ChannelName string    `json:"channel_name,omitempty"`
+	RoomID      int       `json:"room_id,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+var (
+	ErrNoChannel = errors.New("no channel param")
+	ErrNoUser    = errors.New("no user param")
+)
+
+// requireChannel returns ErrNoChannel unless the first param is a channel name
+func requireChannel(params parser.Params) error {
+	if len(params.Channel()) == 0 {
+		return ErrNoChannel
+	}
+	return nil
+}
+
+// IsChatCleared returns whether the CLEARCHAT is clearing the whole chat rather than banning a single user
+func IsChatCleared(message parser.Message) bool {
+	return len(message.Params) == 1
+}
+
+func NewChatCleared(message parser.Message) (*ChatCleared, error) {
+	if err := requireChannel(message.Params); err != nil {
+		return nil, err
+	}
+	var err error
+	c := &ChatCleared{
+		ChannelName: message.Params.Channel(),
+	}
+	if c.RoomID, err = strconv.Atoi(message.Tags["room-id"]); err != nil {
+		return nil, fmt.Errorf("failed to parse room-id as int: %w", err)
+	}
+	if c.Time, err = timeFromTmiSentTs(message.Tags); err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp as time: %w", err)
+	}
+	return c, nil
+}
+
 // TODO: Should we be wrapping the lower level errors in this?
 func NewBan(message parser.Message) (*Ban, error) {
+	if err := requireChannel(message.Params); err != nil {
+		return nil, err
+	}
+	if len(message.Params) < 2 {
+		return nil, ErrNoUser
+	}
 	tags := message.Tags
 	var err error
 	b := &Ban{
@@ -29,7 +78,7 @@ func NewBan(message parser.Message) (*Ban, error) {
 		UserName:    message.Params[1],
 	}
 	// Target message ID is optional
-	if msgId, hasMsgId := tags["target-msg-id"]; hasMsgId {
+	if msgId := tags["target-msg-id"]; len(msgId) > 0 {
 		var id uuid.UUID
 		if id, err = uuid.Parse(msgId); err != nil {
 			return nil, fmt.Errorf("failed to parse message id as uuid: %w", err)
@@ -37,7 +86,7 @@ func NewBan(message parser.Message) (*Ban, error) {
 		b.TargetMessageID = &id
 	}
 	// Ban duration is optional, if not provided it's a permanent ban
-	if durString, hasDuration := tags["ban-duration"]; !hasDuration {
+	if durString := tags["ban-duration"]; len(durString) == 0 {
 		b.Permanent = true
 	} else {
 		var durSec int
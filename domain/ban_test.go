@@ -39,4 +39,63 @@ func TestNewBan(t *testing.T) {
 			UserName:        "user",
 		}, *b)
 	})
+
+	t.Run("Empty optional tags", func(t *testing.T) {
+		b, err := NewBan(parser.Message{
+			Tags: map[string]string{
+				"target-msg-id":  "",
+				"ban-duration":   "",
+				"room-id":        "2",
+				"tmi-sent-ts":    "1642715756806",
+				"target-user-id": "3",
+			},
+			Command: "CLEARCHAT",
+			Params:  []string{"#channel", "user"},
+		})
+		assert.NoError(t, err)
+		assert.True(t, b.Permanent)
+		assert.Nil(t, b.TargetMessageID)
+	})
+
+	t.Run("No user", func(t *testing.T) {
+		_, err := NewBan(parser.Message{Command: "CLEARCHAT", Params: []string{"#channel"}})
+		assert.ErrorIs(t, err, ErrNoUser)
+	})
+
+	t.Run("No channel", func(t *testing.T) {
+		_, err := NewBan(parser.Message{Command: "CLEARCHAT"})
+		assert.ErrorIs(t, err, ErrNoChannel)
+		_, err = NewBan(parser.Message{Command: "CLEARCHAT", Params: []string{"", "user"}})
+		assert.ErrorIs(t, err, ErrNoChannel)
+		// The first param must be a channel, not just any name
+		_, err = NewBan(parser.Message{Command: "CLEARCHAT", Params: []string{"ab", "user"}})
+		assert.ErrorIs(t, err, ErrNoChannel)
+	})
+}
+
+func TestNewChatCleared(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		ts := time.Now().Truncate(time.Millisecond)
+		msg := parser.Message{
+			Tags: map[string]string{
+				"room-id":     "2",
+				"tmi-sent-ts": strconv.FormatInt(ts.UnixNano()/int64(time.Millisecond), 10),
+			},
+			Command: "CLEARCHAT",
+			Params:  []string{"#channel"},
+		}
+		assert.True(t, IsChatCleared(msg))
+		c, err := NewChatCleared(msg)
+		assert.NoError(t, err)
+		assert.Equal(t, ChatCleared{
+			ChannelName: "channel",
+			RoomID:      2,
+			Time:        ts,
+		}, *c)
+	})
+
+	t.Run("No channel", func(t *testing.T) {
+		_, err := NewChatCleared(parser.Message{Command: "CLEARCHAT"})
+		assert.ErrorIs(t, err, ErrNoChannel)
+	})
 }
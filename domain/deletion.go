@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/google/uuid"
+)
+
+// MessageDeletion is a single chat message removed by a moderator
+type MessageDeletion struct {
+	// ChannelName is the name of the channel which the message was deleted from
+	ChannelName string
+	// RoomID is the ID of the channel, 0 if the server sent it empty
+	RoomID int
+	// UserName is the login of the user who sent the deleted message
+	UserName string
+	// TargetMessageID is the ID of the deleted ChatMessage
+	TargetMessageID uuid.UUID
+	// Message is the text of the deleted message
+	Message string
+	// Time is the time that the message was deleted
+	Time time.Time
+}
+
+func NewMessageDeletion(message parser.Message) (*MessageDeletion, error) {
+	if err := requireChannel(message.Params); err != nil {
+		return nil, err
+	}
+	tags := message.Tags
+	var err error
+	d := &MessageDeletion{
+		ChannelName: message.Params.Channel(),
+		UserName:    tags["login"],
+	}
+	if len(message.Params) > 1 {
+		d.Message = message.Params[1]
+	}
+	if d.TargetMessageID, err = uuid.Parse(tags["target-msg-id"]); err != nil {
+		return nil, fmt.Errorf("failed to parse target message id as uuid: %w", err)
+	}
+	// Twitch documents room-id as sometimes being empty on CLEARMSG
+	if roomID := tags["room-id"]; len(roomID) > 0 {
+		if d.RoomID, err = strconv.Atoi(roomID); err != nil {
+			return nil, fmt.Errorf("failed to parse room-id as int: %w", err)
+		}
+	}
+	if d.Time, err = timeFromTmiSentTs(tags); err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp as time: %w", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessageDeletion(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		ts := time.Now().Truncate(time.Millisecond)
+		id := uuid.New()
+		msg := parser.Message{
+			Tags: map[string]string{
+				"login":         "user",
+				"room-id":       "2",
+				"target-msg-id": id.String(),
+				"tmi-sent-ts":   strconv.FormatInt(ts.UnixNano()/int64(time.Millisecond), 10),
+			},
+			Command: "CLEARMSG",
+			Params:  []string{"#channel", "deleted message"},
+		}
+		d, err := NewMessageDeletion(msg)
+		assert.NoError(t, err)
+		assert.Equal(t, MessageDeletion{
+			ChannelName:     "channel",
+			RoomID:          2,
+			UserName:        "user",
+			TargetMessageID: id,
+			Message:         "deleted message",
+			Time:            ts,
+		}, *d)
+	})
+
+	t.Run("Empty room-id", func(t *testing.T) {
+		d, err := NewMessageDeletion(parser.Message{
+			Tags:    map[string]string{"login": "user", "room-id": "", "target-msg-id": uuid.NewString(), "tmi-sent-ts": "1642720582342"},
+			Command: "CLEARMSG",
+			Params:  []string{"#channel", "deleted message"},
+		})
+		assert.NoError(t, err)
+		assert.Zero(t, d.RoomID)
+	})
+
+	t.Run("Invalid room-id", func(t *testing.T) {
+		_, err := NewMessageDeletion(parser.Message{
+			Tags:    map[string]string{"login": "user", "room-id": "abc", "target-msg-id": uuid.NewString(), "tmi-sent-ts": "1642720582342"},
+			Command: "CLEARMSG",
+			Params:  []string{"#channel", "deleted message"},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid target", func(t *testing.T) {
+		_, err := NewMessageDeletion(parser.Message{
+			Tags:    map[string]string{"login": "user", "target-msg-id": "abc"},
+			Command: "CLEARMSG",
+			Params:  []string{"#channel", "deleted message"},
+		})
+		assert.Error(t, err)
+	})
+}
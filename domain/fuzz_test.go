@@ -0,0 +1,41 @@
+//go:build go1.18
+// +build go1.18
+
+package domain
+
+import "testing"
+
+func FuzzMakeChatMessage(f *testing.F) {
+	f.Add("@badges=subscriber/3;display-name=user;id=6b4b5a3e-6f0e-4a2b-9d35-1b2c3d4e5f60;mod=1;room-id=2;tmi-sent-ts=1642715756806;user-id=1 :user!user@user.tmi.twitch.tv PRIVMSG #channel :message")
+	f.Add(":user!user@user.tmi.twitch.tv PRIVMSG #channel")
+	f.Add("PRIVMSG :")
+	f.Fuzz(func(t *testing.T, line string) {
+		message, err := parseMessage(line)
+		if err != nil {
+			t.Skip()
+		}
+		_, _ = MakeChatMessage(message)
+	})
+}
+
+func FuzzNewBan(f *testing.F) {
+	f.Add("@ban-duration=1;room-id=2;target-msg-id=6b4b5a3e-6f0e-4a2b-9d35-1b2c3d4e5f60;target-user-id=3;tmi-sent-ts=1642715756806 :tmi.twitch.tv CLEARCHAT #channel :user")
+	f.Add("@ban-duration=;room-id=2;target-user-id=3;tmi-sent-ts=1642715756806 :tmi.twitch.tv CLEARCHAT #channel :user")
+	f.Add(":tmi.twitch.tv CLEARCHAT #channel")
+	f.Fuzz(func(t *testing.T, line string) {
+		message, err := parseMessage(line)
+		if err != nil {
+			t.Skip()
+		}
+		_, _ = NewBan(message)
+	})
+}
+
+func FuzzNewBadges(f *testing.F) {
+	f.Add("subscriber/6,bits/75000")
+	f.Add("broadcaster/1,")
+	f.Add("/")
+	f.Fuzz(func(t *testing.T, badges string) {
+		_, _ = NewBadges(badges)
+	})
+}
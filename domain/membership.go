@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+)
+
+type MembershipType string
+
+const (
+	MembershipJoin MembershipType = "join"
+	MembershipPart MembershipType = "part"
+)
+
+var ErrUnsupportedMembership = errors.New("message isn't a JOIN or PART")
+
+// Membership is a user joining or leaving a channel, Twitch only sends these with the membership capability
+type Membership struct {
+	Type MembershipType
+	// ChannelName is the name of the channel which was joined or left
+	ChannelName string
+	// Source is the full hostmask of the user
+	Source parser.Source
+	// Time is when the server sent the message, or when it was received if the server didn't include it
+	Time time.Time
+}
+
+func NewMembership(message parser.Message) (*Membership, error) {
+	m := &Membership{}
+	switch message.Command {
+	case "JOIN":
+		m.Type = MembershipJoin
+	case "PART":
+		m.Type = MembershipPart
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedMembership, message.Command)
+	}
+	if err := requireChannel(message.Params); err != nil {
+		return nil, err
+	}
+	if len(message.Prefix) == 0 {
+		return nil, ErrNoUser
+	}
+	m.ChannelName = message.Params.Channel()
+	m.Source = message.Prefix.Source()
+	var err error
+	if m.Time, err = serverTime(message.Tags); err != nil {
+		return nil, fmt.Errorf("failed to parse time: %w", err)
+	}
+	return m, nil
+}
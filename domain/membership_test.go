@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMembership(t *testing.T) {
+	t.Run("Join", func(t *testing.T) {
+		m, err := NewMembership(parser.Message{
+			Tags:    parser.Tags{"time": "2022-01-20T21:55:56.806Z"},
+			Prefix:  "nick!~user@host.example.com",
+			Command: "JOIN",
+			Params:  parser.Params{"#channel"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, Membership{
+			Type:        MembershipJoin,
+			ChannelName: "channel",
+			Source:      parser.Source{Name: "nick", User: "~user", Host: "host.example.com"},
+			Time:        time.Date(2022, 1, 20, 21, 55, 56, 806000000, time.UTC),
+		}, *m)
+	})
+
+	t.Run("Part without server time", func(t *testing.T) {
+		m, err := NewMembership(parser.Message{
+			Prefix:  "user!user@user.tmi.twitch.tv",
+			Command: "PART",
+			Params:  parser.Params{"#channel"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, MembershipPart, m.Type)
+		assert.WithinDuration(t, time.Now(), m.Time, time.Second)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := NewMembership(parser.Message{Prefix: "user", Command: "JOIN"})
+		assert.ErrorIs(t, err, ErrNoChannel)
+		_, err = NewMembership(parser.Message{Command: "JOIN", Params: parser.Params{"#channel"}})
+		assert.ErrorIs(t, err, ErrNoUser)
+		_, err = NewMembership(parser.Message{Prefix: "user", Command: "PRIVMSG", Params: parser.Params{"#channel"}})
+		assert.ErrorIs(t, err, ErrUnsupportedMembership)
+	})
+}
@@ -19,6 +19,8 @@ type (
 		ChannelName string
 		// UserName is the name of the user who sent the message
 		UserName string
+		// Source is the full hostmask of the user who sent the message
+		Source parser.Source
 		// Message is the actual message text
 		Message string
 		// Time is the time that the IRC server received the message
@@ -40,7 +42,10 @@ type (
 	}
 )
 
-var ErrInvalidBadge = errors.New("badge provided was invalid")
+var (
+	ErrInvalidBadge = errors.New("badge provided was invalid")
+	ErrNoMessage    = errors.New("no message param")
+)
 
 func NewBadge(name string) (b Badge, err error) {
 	if len(name) == 0 {
@@ -72,11 +77,18 @@ func NewBadges(name string) (b []Badge, err error) {
 // TODO: Should we be wrapping the lower level errors in this?
 // TODO: Handle if we don't get these tags, should only happen if we don't request for capabilities
 func MakeChatMessage(message parser.Message) (*ChatMessage, error) {
+	if err := requireChannel(message.Params); err != nil {
+		return nil, err
+	}
+	if len(message.Params) < 2 {
+		return nil, ErrNoMessage
+	}
 	tags := message.Tags
 	var err error
 	c := &ChatMessage{
 		ChannelName: message.Params.Channel(),
 		UserName:    tags["display-name"],
+		Source:      message.Prefix.Source(),
 		Message:     message.Params[1],
 		Mod:         tags["mod"] == "1",
 	}
@@ -74,7 +74,7 @@ func TestMakeChatMessage(t *testing.T) {
 				"room-id":      "2",
 				"badges":       "subscriber/3",
 			},
-			Prefix:  "",
+			Prefix:  "user!user@user.tmi.twitch.tv",
 			Command: "PRIVMSG",
 			Params: []string{
 				"#channel",
@@ -87,6 +87,7 @@ func TestMakeChatMessage(t *testing.T) {
 			ID:          id,
 			ChannelName: "channel",
 			UserName:    "user",
+			Source:      parser.Source{Name: "user", User: "user", Host: "user.tmi.twitch.tv"},
 			Message:     "message",
 			Time:        ts,
 			UserID:      1,
@@ -95,4 +96,16 @@ func TestMakeChatMessage(t *testing.T) {
 			Badges:      []Badge{{"subscriber", "3"}},
 		}, *chatMessage)
 	})
+
+	t.Run("No channel", func(t *testing.T) {
+		_, err := MakeChatMessage(parser.Message{Command: "PRIVMSG"})
+		assert.ErrorIs(t, err, ErrNoChannel)
+		_, err = MakeChatMessage(parser.Message{Command: "PRIVMSG", Params: []string{"", "message"}})
+		assert.ErrorIs(t, err, ErrNoChannel)
+	})
+
+	t.Run("No message", func(t *testing.T) {
+		_, err := MakeChatMessage(parser.Message{Command: "PRIVMSG", Params: []string{"#channel"}})
+		assert.ErrorIs(t, err, ErrNoMessage)
+	})
 }
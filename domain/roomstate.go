@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+)
+
+type (
+	// RoomState is the chat settings of a channel
+	RoomState struct {
+		// ChannelName is the name of the channel the settings are for
+		ChannelName string
+		// RoomID is the ID of the Channel
+		RoomID int
+		// EmoteOnly is whether messages can only contain emotes
+		EmoteOnly bool
+		// FollowersOnly is whether only followers can chat
+		FollowersOnly bool
+		// FollowersOnlyDuration is how long users must have followed for before they can chat
+		FollowersOnlyDuration time.Duration
+		// R9K is whether messages must be unique
+		R9K bool
+		// Slow is how long users must wait between messages
+		Slow time.Duration
+		// SubsOnly is whether only subscribers can chat
+		SubsOnly bool
+	}
+
+	// RoomStateUpdate is a partial RoomState, only the settings which are set have been updated
+	RoomStateUpdate struct {
+		ChannelName           string
+		RoomID                int
+		EmoteOnly             *bool
+		FollowersOnly         *bool
+		FollowersOnlyDuration *time.Duration
+		R9K                   *bool
+		Slow                  *time.Duration
+		SubsOnly              *bool
+	}
+
+	// RoomStateChange is the settings which changed, along with the full RoomState after the change
+	RoomStateChange struct {
+		Changes RoomStateUpdate
+		State   RoomState
+	}
+)
+
+// NewRoomStateUpdate maps a ROOMSTATE into the settings it contains
+// Twitch sends every setting when joining a channel, then only the setting that changed
+func NewRoomStateUpdate(message parser.Message) (*RoomStateUpdate, error) {
+	if err := requireChannel(message.Params); err != nil {
+		return nil, err
+	}
+	tags := message.Tags
+	var err error
+	u := &RoomStateUpdate{
+		ChannelName: message.Params.Channel(),
+		EmoteOnly:   boolTag(tags, "emote-only"),
+		R9K:         boolTag(tags, "r9k"),
+		SubsOnly:    boolTag(tags, "subs-only"),
+	}
+	if u.RoomID, err = strconv.Atoi(tags["room-id"]); err != nil {
+		return nil, fmt.Errorf("unable to convert room-id into int: %w", err)
+	}
+	// Empty tags are the same as missing tags
+	if v := tags["followers-only"]; len(v) > 0 {
+		mins, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse followers-only as int: %w", err)
+		}
+		// -1 means followers only is disabled, otherwise it's the minutes they must have followed for
+		enabled := mins >= 0
+		dur := time.Duration(0)
+		if enabled {
+			dur = time.Duration(mins) * time.Minute
+		}
+		u.FollowersOnly = &enabled
+		u.FollowersOnlyDuration = &dur
+	}
+	if v := tags["slow"]; len(v) > 0 {
+		secs, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse slow as int: %w", err)
+		}
+		dur := time.Duration(secs) * time.Second
+		u.Slow = &dur
+	}
+	return u, nil
+}
+
+// Apply merges the update into the RoomState, returning the new state & only the settings which actually changed
+func (s RoomState) Apply(u RoomStateUpdate) (RoomState, RoomStateUpdate) {
+	changes := RoomStateUpdate{
+		ChannelName: u.ChannelName,
+		RoomID:      u.RoomID,
+	}
+	s.ChannelName = u.ChannelName
+	s.RoomID = u.RoomID
+	changes.EmoteOnly = applyBool(&s.EmoteOnly, u.EmoteOnly)
+	changes.FollowersOnly = applyBool(&s.FollowersOnly, u.FollowersOnly)
+	changes.FollowersOnlyDuration = applyDuration(&s.FollowersOnlyDuration, u.FollowersOnlyDuration)
+	changes.R9K = applyBool(&s.R9K, u.R9K)
+	changes.Slow = applyDuration(&s.Slow, u.Slow)
+	changes.SubsOnly = applyBool(&s.SubsOnly, u.SubsOnly)
+	return s, changes
+}
+
+// HasChanges returns whether any settings are set in the update
+func (u RoomStateUpdate) HasChanges() bool {
+	return u.EmoteOnly != nil || u.FollowersOnly != nil || u.FollowersOnlyDuration != nil ||
+		u.R9K != nil || u.Slow != nil || u.SubsOnly != nil
+}
+
+// applyBool sets current to the update, returning the update only if it changed the value
+func applyBool(current *bool, update *bool) *bool {
+	if update == nil || *current == *update {
+		return nil
+	}
+	*current = *update
+	return update
+}
+
+// applyDuration sets current to the update, returning the update only if it changed the value
+func applyDuration(current *time.Duration, update *time.Duration) *time.Duration {
+	if update == nil || *current == *update {
+		return nil
+	}
+	*current = *update
+	return update
+}
+
+// boolTag parses an optional "0" or "1" tag, returning nil if it's missing or empty
+func boolTag(tags parser.Tags, key string) *bool {
+	v := tags[key]
+	if len(v) == 0 {
+		return nil
+	}
+	b := v == "1"
+	return &b
+}
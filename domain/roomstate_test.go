@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRoomStateUpdate(t *testing.T) {
+	t.Run("Full", func(t *testing.T) {
+		u, err := NewRoomStateUpdate(parser.Message{
+			Tags: map[string]string{
+				"emote-only":     "0",
+				"followers-only": "10",
+				"r9k":            "1",
+				"room-id":        "12345",
+				"slow":           "30",
+				"subs-only":      "0",
+			},
+			Command: "ROOMSTATE",
+			Params:  []string{"#channel"},
+		})
+		require.NoError(t, err)
+		f, tr := false, true
+		followers, slow := 10*time.Minute, 30*time.Second
+		assert.Equal(t, RoomStateUpdate{
+			ChannelName:           "channel",
+			RoomID:                12345,
+			EmoteOnly:             &f,
+			FollowersOnly:         &tr,
+			FollowersOnlyDuration: &followers,
+			R9K:                   &tr,
+			Slow:                  &slow,
+			SubsOnly:              &f,
+		}, *u)
+	})
+
+	t.Run("Followers only disabled", func(t *testing.T) {
+		u, err := NewRoomStateUpdate(parser.Message{
+			Tags:    map[string]string{"followers-only": "-1", "room-id": "1"},
+			Command: "ROOMSTATE",
+			Params:  []string{"#channel"},
+		})
+		require.NoError(t, err)
+		assert.False(t, *u.FollowersOnly)
+		assert.Equal(t, time.Duration(0), *u.FollowersOnlyDuration)
+		assert.Nil(t, u.Slow)
+	})
+
+	t.Run("Empty tags", func(t *testing.T) {
+		u, err := NewRoomStateUpdate(parser.Message{
+			Tags:    map[string]string{"emote-only": "", "slow": "", "room-id": "1"},
+			Command: "ROOMSTATE",
+			Params:  []string{"#channel"},
+		})
+		require.NoError(t, err)
+		assert.False(t, u.HasChanges())
+	})
+
+	t.Run("Invalid slow", func(t *testing.T) {
+		_, err := NewRoomStateUpdate(parser.Message{
+			Tags:    map[string]string{"slow": "abc", "room-id": "1"},
+			Command: "ROOMSTATE",
+			Params:  []string{"#channel"},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestRoomState_Apply(t *testing.T) {
+	tr := true
+	slow := 30 * time.Second
+	current := RoomState{ChannelName: "channel", RoomID: 1, R9K: true}
+	state, changes := current.Apply(RoomStateUpdate{
+		ChannelName: "channel",
+		RoomID:      1,
+		R9K:         &tr,
+		Slow:        &slow,
+	})
+	assert.Equal(t, RoomState{ChannelName: "channel", RoomID: 1, R9K: true, Slow: slow}, state)
+	// R9K was already enabled so only slow changed
+	assert.Equal(t, RoomStateUpdate{ChannelName: "channel", RoomID: 1, Slow: &slow}, changes)
+	assert.True(t, changes.HasChanges())
+
+	_, changes = state.Apply(RoomStateUpdate{ChannelName: "channel", RoomID: 1, Slow: &slow})
+	assert.False(t, changes.HasChanges())
+}
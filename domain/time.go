@@ -15,3 +15,12 @@ func timeFromTmiSentTs(tags parser.Tags) (time.Time, error) {
 	t := time.Unix(0, int64(ts*int(time.Millisecond)))
 	return t, nil
 }
+
+// serverTime parses the IRCv3 server-time tag, falling back to now if the server didn't send one
+func serverTime(tags parser.Tags) (time.Time, error) {
+	v := tags["time"]
+	if len(v) == 0 {
+		return time.Now(), nil
+	}
+	return time.Parse(time.RFC3339Nano, v)
+}
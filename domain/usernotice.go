@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/google/uuid"
+)
+
+type (
+	// UserNoticeType is the msg-id of a USERNOTICE
+	UserNoticeType string
+
+	// UserNotice is an event announced in a channel, such as a subscription or raid
+	// Only the details matching the Type are set
+	UserNotice struct {
+		// Type is what kind of event the notice is for
+		Type UserNoticeType
+		// ID is the unique ID of the notice
+		ID uuid.UUID
+		// ChannelName is the name of the channel which the notice was sent in
+		ChannelName string
+		// ChannelID is the ID of the Channel
+		ChannelID int
+		// UserName is the login of the user who caused the notice
+		UserName string
+		// DisplayName is the display name of the user who caused the notice
+		DisplayName string
+		// UserID is the ID of the user who caused the notice
+		UserID int
+		// Message is the optional message sent by the user along with the notice
+		Message string
+		// SystemMessage is the message Twitch shows for the notice
+		SystemMessage string
+		// Time is the time that the IRC server sent the notice
+		Time time.Time
+		// Badges is the badges the user has assigned
+		Badges []Badge
+
+		Sub           *Sub
+		SubGift       *SubGift
+		MysteryGift   *MysteryGift
+		Raid          *Raid
+		Ritual        *Ritual
+		BitsBadgeTier *BitsBadgeTier
+	}
+
+	// Sub is the details of a sub or resub
+	Sub struct {
+		CumulativeMonths int
+		// StreakMonths is only set when the user shares their streak
+		StreakMonths int
+		ShareStreak  bool
+		Plan         string
+		PlanName     string
+	}
+
+	// SubGift is the details of a sub gifted to a single user
+	SubGift struct {
+		Months               int
+		GiftMonths           int
+		RecipientID          int
+		RecipientUserName    string
+		RecipientDisplayName string
+		Plan                 string
+		PlanName             string
+	}
+
+	// MysteryGift is the details of subs gifted to random users in the channel
+	MysteryGift struct {
+		GiftCount int
+		// SenderCount is the total amount of subs the user has gifted in the channel, if they've chosen to share it
+		SenderCount int
+		Plan        string
+	}
+
+	// Raid is the details of a channel raiding this channel
+	Raid struct {
+		DisplayName string
+		UserName    string
+		ViewerCount int
+	}
+
+	// Ritual is the details of a ritual such as new_chatter
+	Ritual struct {
+		Name string
+	}
+
+	// BitsBadgeTier is the details of a user earning a new bits badge
+	BitsBadgeTier struct {
+		Threshold int
+	}
+)
+
+const (
+	UserNoticeSub           UserNoticeType = "sub"
+	UserNoticeResub         UserNoticeType = "resub"
+	UserNoticeSubGift       UserNoticeType = "subgift"
+	UserNoticeMysteryGift   UserNoticeType = "submysterygift"
+	UserNoticeRaid          UserNoticeType = "raid"
+	UserNoticeUnraid        UserNoticeType = "unraid"
+	UserNoticeRitual        UserNoticeType = "ritual"
+	UserNoticeBitsBadgeTier UserNoticeType = "bitsbadgetier"
+)
+
+var ErrUnsupportedUserNotice = errors.New("unsupported user notice type")
+
+// NewUserNotice maps a USERNOTICE message into a UserNotice
+// returns ErrUnsupportedUserNotice for msg-ids which aren't mapped
+func NewUserNotice(message parser.Message) (*UserNotice, error) {
+	if err := requireChannel(message.Params); err != nil {
+		return nil, err
+	}
+	tags := message.Tags
+	var err error
+	n := &UserNotice{
+		Type:          UserNoticeType(tags["msg-id"]),
+		ChannelName:   message.Params.Channel(),
+		UserName:      tags["login"],
+		DisplayName:   tags["display-name"],
+		SystemMessage: tags["system-msg"],
+	}
+	if len(message.Params) > 1 {
+		n.Message = message.Params[1]
+	}
+	switch n.Type {
+	case UserNoticeSub, UserNoticeResub:
+		n.Sub, err = newSub(tags)
+	case UserNoticeSubGift:
+		n.SubGift, err = newSubGift(tags)
+	case UserNoticeMysteryGift:
+		n.MysteryGift, err = newMysteryGift(tags)
+	case UserNoticeRaid:
+		n.Raid, err = newRaid(tags)
+	case UserNoticeUnraid:
+	case UserNoticeRitual:
+		n.Ritual = &Ritual{Name: tags["msg-param-ritual-name"]}
+	case UserNoticeBitsBadgeTier:
+		n.BitsBadgeTier = &BitsBadgeTier{}
+		n.BitsBadgeTier.Threshold, err = intTag(tags, "msg-param-threshold")
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedUserNotice, n.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v params: %w", n.Type, err)
+	}
+	if n.ID, err = uuid.Parse(tags["id"]); err != nil {
+		return nil, fmt.Errorf("unable to parse ID into uuid: %w", err)
+	}
+	if n.Time, err = timeFromTmiSentTs(tags); err != nil {
+		return nil, fmt.Errorf("unable to convert time from timestamp: %w", err)
+	}
+	if n.UserID, err = strconv.Atoi(tags["user-id"]); err != nil {
+		return nil, fmt.Errorf("unable to convert user-id into int: %w", err)
+	}
+	if n.ChannelID, err = strconv.Atoi(tags["room-id"]); err != nil {
+		return nil, fmt.Errorf("unable to convert room-id into int: %w", err)
+	}
+	if n.Badges, err = NewBadges(tags["badges"]); err != nil {
+		return nil, fmt.Errorf("failed to create badges from tags: %w", err)
+	}
+	return n, nil
+}
+
+func newSub(tags parser.Tags) (s *Sub, err error) {
+	s = &Sub{
+		ShareStreak: tags["msg-param-should-share-streak"] == "1",
+		Plan:        tags["msg-param-sub-plan"],
+		PlanName:    tags["msg-param-sub-plan-name"],
+	}
+	if s.CumulativeMonths, err = intTag(tags, "msg-param-cumulative-months"); err != nil {
+		return nil, err
+	}
+	if s.StreakMonths, err = intTag(tags, "msg-param-streak-months"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func newSubGift(tags parser.Tags) (g *SubGift, err error) {
+	g = &SubGift{
+		RecipientUserName:    tags["msg-param-recipient-user-name"],
+		RecipientDisplayName: tags["msg-param-recipient-display-name"],
+		Plan:                 tags["msg-param-sub-plan"],
+		PlanName:             tags["msg-param-sub-plan-name"],
+	}
+	if g.Months, err = intTag(tags, "msg-param-months"); err != nil {
+		return nil, err
+	}
+	if g.GiftMonths, err = intTag(tags, "msg-param-gift-months"); err != nil {
+		return nil, err
+	}
+	if g.RecipientID, err = intTag(tags, "msg-param-recipient-id"); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func newMysteryGift(tags parser.Tags) (g *MysteryGift, err error) {
+	g = &MysteryGift{
+		Plan: tags["msg-param-sub-plan"],
+	}
+	if g.GiftCount, err = intTag(tags, "msg-param-mass-gift-count"); err != nil {
+		return nil, err
+	}
+	if g.SenderCount, err = intTag(tags, "msg-param-sender-count"); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func newRaid(tags parser.Tags) (r *Raid, err error) {
+	r = &Raid{
+		DisplayName: tags["msg-param-displayName"],
+		UserName:    tags["msg-param-login"],
+	}
+	if r.ViewerCount, err = intTag(tags, "msg-param-viewerCount"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// intTag parses an optional int tag, returning 0 if it isn't present
+func intTag(tags parser.Tags, key string) (int, error) {
+	v, ok := tags[key]
+	if !ok || len(v) == 0 {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %v as int: %w", key, err)
+	}
+	return i, nil
+}
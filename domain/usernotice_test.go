@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseMessage parses a single line, the CRLF is optional
+func parseMessage(line string) (parser.Message, error) {
+	var message parser.Message
+	err := message.UnmarshalText([]byte(line))
+	return message, err
+}
+
+func TestNewUserNotice(t *testing.T) {
+	t.Run("Resub", func(t *testing.T) {
+		msg, err := parseMessage("@badge-info=subscriber/8;badges=subscriber/6,bits/75000;color=#1E90FF;display-name=Ovojaytee;emotes=;flags=;id=aa52e1d2-6ff5-42ba-b205-9d4a15f9dbf8;login=ovojaytee;mod=0;msg-id=resub;msg-param-cumulative-months=7;msg-param-months=0;msg-param-should-share-streak=1;msg-param-streak-months=8;msg-param-sub-plan-name=Channel\\sSubscription\\s(loeya);msg-param-sub-plan=1000;room-id=166279350;subscriber=1;system-msg=Ovojaytee\\ssubscribed\\sat\\sTier\\s1.;tmi-sent-ts=1558352544376;user-id=160605648;user-type= :tmi.twitch.tv USERNOTICE #loeya :Wow 8 months\r\n")
+		require.NoError(t, err)
+		n, err := NewUserNotice(msg)
+		require.NoError(t, err)
+		assert.Equal(t, UserNotice{
+			Type:          UserNoticeResub,
+			ID:            uuid.MustParse("aa52e1d2-6ff5-42ba-b205-9d4a15f9dbf8"),
+			ChannelName:   "loeya",
+			ChannelID:     166279350,
+			UserName:      "ovojaytee",
+			DisplayName:   "Ovojaytee",
+			UserID:        160605648,
+			Message:       "Wow 8 months",
+			SystemMessage: "Ovojaytee subscribed at Tier 1.",
+			Time:          time.Unix(0, 1558352544376*int64(time.Millisecond)),
+			Badges:        []Badge{{"subscriber", "6"}, {"bits", "75000"}},
+			Sub: &Sub{
+				CumulativeMonths: 7,
+				StreakMonths:     8,
+				ShareStreak:      true,
+				Plan:             "1000",
+				PlanName:         "Channel Subscription (loeya)",
+			},
+		}, *n)
+	})
+
+	t.Run("Raid", func(t *testing.T) {
+		msg, err := parseMessage("@badges=;display-name=Raider;id=3d830f12-795c-447d-af3c-ea05e40fbddb;login=raider;msg-id=raid;msg-param-displayName=Raider;msg-param-login=raider;msg-param-viewerCount=15;room-id=1;system-msg=15\\sraiders;tmi-sent-ts=1507246572675;user-id=2 :tmi.twitch.tv USERNOTICE #channel\r\n")
+		require.NoError(t, err)
+		n, err := NewUserNotice(msg)
+		require.NoError(t, err)
+		assert.Equal(t, UserNoticeRaid, n.Type)
+		assert.Empty(t, n.Message)
+		assert.Equal(t, &Raid{DisplayName: "Raider", UserName: "raider", ViewerCount: 15}, n.Raid)
+		assert.Nil(t, n.Sub)
+	})
+
+	t.Run("Sub gift", func(t *testing.T) {
+		msg, err := parseMessage("@badges=;display-name=Gifter;id=3d830f12-795c-447d-af3c-ea05e40fbddb;login=gifter;msg-id=subgift;msg-param-months=3;msg-param-recipient-display-name=Recipient;msg-param-recipient-id=55;msg-param-recipient-user-name=recipient;msg-param-sub-plan=1000;msg-param-sub-plan-name=Sub;room-id=1;tmi-sent-ts=1507246572675;user-id=2 :tmi.twitch.tv USERNOTICE #channel\r\n")
+		require.NoError(t, err)
+		n, err := NewUserNotice(msg)
+		require.NoError(t, err)
+		assert.Equal(t, &SubGift{
+			Months:               3,
+			RecipientID:          55,
+			RecipientUserName:    "recipient",
+			RecipientDisplayName: "Recipient",
+			Plan:                 "1000",
+			PlanName:             "Sub",
+		}, n.SubGift)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := NewUserNotice(parser.Message{
+			Tags:    map[string]string{"msg-id": "somethingnew"},
+			Command: "USERNOTICE",
+			Params:  []string{"#channel"},
+		})
+		assert.ErrorIs(t, err, ErrUnsupportedUserNotice)
+	})
+
+	t.Run("Invalid param", func(t *testing.T) {
+		_, err := NewUserNotice(parser.Message{
+			Tags:    map[string]string{"msg-id": "bitsbadgetier", "msg-param-threshold": "lots"},
+			Command: "USERNOTICE",
+			Params:  []string{"#channel"},
+		})
+		assert.Error(t, err)
+	})
+}
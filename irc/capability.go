@@ -0,0 +1,234 @@
+package irc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+)
+
+// https://ircv3.net/specs/extensions/capability-negotiation.html
+
+type (
+	// Cap is an IRCv3 capability, with an optional value such as sasl=PLAIN,EXTERNAL
+	Cap struct {
+		Name  string
+		Value string
+	}
+
+	// CapCommand is an outbound CAP command
+	CapCommand struct {
+		Subcommand string
+		Params     []string
+	}
+
+	// CapNegotiator negotiates IRCv3 capabilities as a state machine, the caller is responsible for sending the commands it returns
+	CapNegotiator struct {
+		want      []string
+		available map[string]Cap
+		enabled   map[string]Cap
+		rejected  map[string]struct{}
+		// pending is the capabilities requested without an ACK or NAK yet
+		pending map[string]struct{}
+		done    bool
+		// holdEnd leaves sending CAP END to the caller, such as after SASL authentication
+		holdEnd bool
+	}
+)
+
+// CapLSVersion is the version of capability negotiation supported, enabling multiline LS & values
+const CapLSVersion = "302"
+
+// ParseCap parses a single capability, splitting the value if it has one
+func ParseCap(s string) Cap {
+	name, value := s, ""
+	if i := strings.IndexByte(s, '='); i >= 0 {
+		name, value = s[:i], s[i+1:]
+	}
+	return Cap{Name: name, Value: value}
+}
+
+// ParseCaps parses a space separated list of capabilities
+func ParseCaps(list string) []Cap {
+	fields := strings.Fields(list)
+	caps := make([]Cap, len(fields))
+	for i, f := range fields {
+		caps[i] = ParseCap(f)
+	}
+	return caps
+}
+
+func (c Cap) String() string {
+	if len(c.Value) == 0 {
+		return c.Name
+	}
+	return c.Name + "=" + c.Value
+}
+
+// Values splits a comma separated value, such as the mechanisms of sasl=PLAIN,EXTERNAL
+func (c Cap) Values() []string {
+	if len(c.Value) == 0 {
+		return nil
+	}
+	return strings.Split(c.Value, ",")
+}
+
+func (c CapCommand) message() parser.Message {
+	return parser.Message{Command: Capability, Params: append([]string{c.Subcommand}, c.Params...)}
+}
+
+func (c CapCommand) Bytes() []byte {
+	return c.message().Bytes()
+}
+
+// MarshalText rejects params which would be sent as more than one param
+func (c CapCommand) MarshalText() ([]byte, error) {
+	return c.message().MarshalText()
+}
+
+// NewCapNegotiator creates a negotiator which requests each of the wanted capabilities the server supports
+func NewCapNegotiator(want ...string) *CapNegotiator {
+	return &CapNegotiator{
+		want:      want,
+		available: make(map[string]Cap),
+		enabled:   make(map[string]Cap),
+		rejected:  make(map[string]struct{}),
+		pending:   make(map[string]struct{}),
+	}
+}
+
+// Begin returns the command to list the server's capabilities, this should be sent before registering
+func (n *CapNegotiator) Begin() CapCommand {
+	return CapCommand{Subcommand: "LS", Params: []string{CapLSVersion}}
+}
+
+// HoldEnd stops Handle returning CAP END, the caller should send End once it's finished registering
+func (n *CapNegotiator) HoldEnd() {
+	n.holdEnd = true
+}
+
+// End returns the command to finish negotiation
+func (n *CapNegotiator) End() CapCommand {
+	return CapCommand{Subcommand: "END"}
+}
+
+// Handle processes a CAP message from the server, returning any commands to send in response
+// done is true once negotiation has finished & CAP END has been returned, or is being held
+func (n *CapNegotiator) Handle(message parser.Message) (replies []CapCommand, done bool, err error) {
+	// :server CAP <target> <subcommand> [*] :<caps>
+	if message.Command != Capability || len(message.Params) < 3 {
+		return nil, n.done, fmt.Errorf("invalid CAP message: %v", message.Params)
+	}
+	subcommand, params := message.Params[1], message.Params[2:]
+	// A * before the caps means there are more lines to come
+	more := len(params) > 1 && params[0] == "*"
+	caps := ParseCaps(params[len(params)-1])
+	switch subcommand {
+	case "LS", "NEW":
+		for _, c := range caps {
+			n.available[c.Name] = c
+		}
+		if more {
+			return nil, n.done, nil
+		}
+		// Wanted capabilities added by NEW after negotiation can still be requested
+		if req := n.request(); len(req) > 0 {
+			return []CapCommand{{Subcommand: "REQ", Params: []string{strings.Join(req, " ")}}}, n.done, nil
+		}
+		if n.done {
+			return nil, true, nil
+		}
+		return n.end(), true, nil
+	case "ACK":
+		for _, c := range caps {
+			name := c.Name
+			delete(n.pending, strings.TrimPrefix(name, "-"))
+			// A - prefix acknowledges disabling the capability
+			if strings.HasPrefix(name, "-") {
+				delete(n.enabled, name[1:])
+				continue
+			}
+			n.enabled[name] = n.availableOrBare(c)
+		}
+	case "NAK":
+		for _, c := range caps {
+			delete(n.pending, c.Name)
+			n.rejected[c.Name] = struct{}{}
+		}
+	case "DEL":
+		for _, c := range caps {
+			delete(n.available, c.Name)
+			delete(n.enabled, c.Name)
+		}
+		return nil, n.done, nil
+	default:
+		return nil, n.done, nil
+	}
+	if len(n.pending) > 0 || n.done {
+		return nil, n.done, nil
+	}
+	return n.end(), true, nil
+}
+
+// Enabled returns the capability if the server has acknowledged it
+func (n *CapNegotiator) Enabled(name string) (Cap, bool) {
+	c, ok := n.enabled[name]
+	return c, ok
+}
+
+// Available returns the capability if the server supports it
+func (n *CapNegotiator) Available(name string) (Cap, bool) {
+	c, ok := n.available[name]
+	return c, ok
+}
+
+// Rejected returns whether the server NAKed the capability
+func (n *CapNegotiator) Rejected(name string) bool {
+	_, ok := n.rejected[name]
+	return ok
+}
+
+// EnabledCaps is every capability the server has acknowledged, sorted by name
+func (n *CapNegotiator) EnabledCaps() []Cap {
+	caps := make([]Cap, 0, len(n.enabled))
+	for _, c := range n.enabled {
+		caps = append(caps, c)
+	}
+	sort.Slice(caps, func(i, j int) bool {
+		return caps[i].Name < caps[j].Name
+	})
+	return caps
+}
+
+// request finds the wanted capabilities which are available, marking them as pending
+func (n *CapNegotiator) request() []string {
+	var req []string
+	for _, name := range n.want {
+		if _, ok := n.available[name]; !ok {
+			continue
+		}
+		if _, ok := n.enabled[name]; ok {
+			continue
+		}
+		n.pending[name] = struct{}{}
+		req = append(req, name)
+	}
+	return req
+}
+
+// availableOrBare returns the capability with the value from LS, as ACKs don't include values
+func (n *CapNegotiator) availableOrBare(c Cap) Cap {
+	if available, ok := n.available[c.Name]; ok {
+		return available
+	}
+	return c
+}
+
+func (n *CapNegotiator) end() []CapCommand {
+	n.done = true
+	if n.holdEnd {
+		return nil
+	}
+	return []CapCommand{n.End()}
+}
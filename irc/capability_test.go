@@ -0,0 +1,115 @@
+package irc
+
+import (
+	"testing"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func capMessage(params ...string) parser.Message {
+	return parser.Message{Prefix: "server", Command: "CAP", Params: append([]string{"*"}, params...)}
+}
+
+func TestParseCaps(t *testing.T) {
+	caps := ParseCaps("multi-prefix sasl=PLAIN,EXTERNAL draft/chathistory=")
+	assert.Equal(t, []Cap{
+		{Name: "multi-prefix"},
+		{Name: "sasl", Value: "PLAIN,EXTERNAL"},
+		{Name: "draft/chathistory"},
+	}, caps)
+	assert.Equal(t, []string{"PLAIN", "EXTERNAL"}, caps[1].Values())
+	assert.Equal(t, "sasl=PLAIN,EXTERNAL", caps[1].String())
+}
+
+func TestCapCommand_Bytes(t *testing.T) {
+	assert.Equal(t, "CAP LS 302", string(CapCommand{Subcommand: "LS", Params: []string{"302"}}.Bytes()))
+	assert.Equal(t, "CAP REQ :sasl multi-prefix", string(CapCommand{Subcommand: "REQ", Params: []string{"sasl multi-prefix"}}.Bytes()))
+	assert.Equal(t, "CAP END", string(CapCommand{Subcommand: "END"}.Bytes()))
+}
+
+func TestCapNegotiator(t *testing.T) {
+	t.Run("Multiline LS", func(t *testing.T) {
+		n := NewCapNegotiator("sasl", "message-tags", "unsupported")
+		assert.Equal(t, "CAP LS 302", string(n.Begin().Bytes()))
+
+		replies, done, err := n.Handle(capMessage("LS", "*", "multi-prefix sasl=PLAIN,EXTERNAL"))
+		require.NoError(t, err)
+		assert.False(t, done)
+		assert.Empty(t, replies)
+
+		replies, done, err = n.Handle(capMessage("LS", "message-tags server-time"))
+		require.NoError(t, err)
+		assert.False(t, done)
+		assert.Equal(t, []CapCommand{{Subcommand: "REQ", Params: []string{"sasl message-tags"}}}, replies)
+
+		replies, done, err = n.Handle(capMessage("ACK", "sasl message-tags"))
+		require.NoError(t, err)
+		assert.True(t, done)
+		assert.Equal(t, []CapCommand{{Subcommand: "END"}}, replies)
+
+		sasl, ok := n.Enabled("sasl")
+		assert.True(t, ok)
+		assert.Equal(t, []string{"PLAIN", "EXTERNAL"}, sasl.Values())
+		assert.Equal(t, []Cap{{Name: "message-tags"}, sasl}, n.EnabledCaps())
+	})
+
+	t.Run("NAK", func(t *testing.T) {
+		n := NewCapNegotiator("sasl")
+		replies, _, err := n.Handle(capMessage("LS", "sasl"))
+		require.NoError(t, err)
+		assert.Len(t, replies, 1)
+		replies, done, err := n.Handle(capMessage("NAK", "sasl"))
+		require.NoError(t, err)
+		assert.True(t, done)
+		assert.Equal(t, []CapCommand{{Subcommand: "END"}}, replies)
+		assert.True(t, n.Rejected("sasl"))
+		_, ok := n.Enabled("sasl")
+		assert.False(t, ok)
+	})
+
+	t.Run("Nothing wanted", func(t *testing.T) {
+		n := NewCapNegotiator("sasl")
+		replies, done, err := n.Handle(capMessage("LS", "multi-prefix"))
+		require.NoError(t, err)
+		assert.True(t, done)
+		assert.Equal(t, []CapCommand{{Subcommand: "END"}}, replies)
+	})
+
+	t.Run("NEW & DEL", func(t *testing.T) {
+		n := NewCapNegotiator("away-notify")
+		_, done, _ := n.Handle(capMessage("LS", "multi-prefix"))
+		assert.True(t, done)
+
+		replies, _, err := n.Handle(capMessage("NEW", "away-notify"))
+		require.NoError(t, err)
+		assert.Equal(t, []CapCommand{{Subcommand: "REQ", Params: []string{"away-notify"}}}, replies)
+		// END has already been sent
+		replies, _, _ = n.Handle(capMessage("ACK", "away-notify"))
+		assert.Empty(t, replies)
+		_, ok := n.Enabled("away-notify")
+		assert.True(t, ok)
+
+		_, _, _ = n.Handle(capMessage("DEL", "away-notify"))
+		_, ok = n.Enabled("away-notify")
+		assert.False(t, ok)
+	})
+
+	t.Run("Hold END", func(t *testing.T) {
+		n := NewCapNegotiator("sasl")
+		n.HoldEnd()
+		_, _, err := n.Handle(capMessage("LS", "sasl"))
+		require.NoError(t, err)
+		replies, done, err := n.Handle(capMessage("ACK", "sasl"))
+		require.NoError(t, err)
+		assert.True(t, done)
+		assert.Empty(t, replies)
+		assert.Equal(t, "CAP END", string(n.End().Bytes()))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, _, err := NewCapNegotiator().Handle(parser.Message{Command: "CAP", Params: []string{"*"}})
+		assert.Error(t, err)
+	})
+}
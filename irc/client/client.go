@@ -2,8 +2,12 @@ package client
 
 import (
 	"context"
+	"encoding"
 	"errors"
+	"fmt"
 	"io"
+	"sync"
+	"time"
 
 	"github.com/ch629/go-irc-kafka/irc/parser"
 )
@@ -17,6 +21,8 @@ type (
 		Input() <-chan parser.Message
 		// Send sends the IrcMessage to the IRC client
 		Send(message ...IrcMessage) error
+		// SendContext sends the IrcMessage to the IRC client, giving up if the context is done while rate limited
+		SendContext(ctx context.Context, message ...IrcMessage) error
 		// Errors is a channel of errors generated when reading or writing to IRC
 		Errors() <-chan error
 		// Closed is whether the client connection is closed
@@ -25,6 +31,8 @@ type (
 		Done() <-chan struct{}
 		// Err contains an error when the client closes suddenly
 		Err() error
+		// Latency is the round trip time of the last keepalive PING, zero without WithKeepAlive
+		Latency() time.Duration
 	}
 
 	IrcMessage interface {
@@ -39,11 +47,37 @@ type (
 		errorChan  chan error
 		scanner    parser.Scanner
 		done       chan struct{}
+		errMux     sync.Mutex
 		err        error
+		limiter    *RateLimiter
+		keepAlive  *keepAlive
+		// splitMessages splits long PRIVMSGs instead of rejecting them
+		splitMessages bool
+		// blockingErrors waits for errors to be read instead of dropping them
+		blockingErrors bool
+		// readTime is the time the last message was read, used for messages without a server-time tag
+		readTime func() time.Time
 	}
+
+	// Option configures optional behaviour of the client
+	Option func(cli *client)
 )
 
-func NewClient(ctx context.Context, conn io.ReadWriteCloser) IrcClient {
+// WithRateLimiter throttles outbound messages, the RateLimiter can be shared between clients on the same account
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(cli *client) {
+		cli.limiter = limiter
+	}
+}
+
+// WithBlockingErrors waits for each error to be read from Errors instead of dropping it, so Errors must always be drained
+func WithBlockingErrors() Option {
+	return func(cli *client) {
+		cli.blockingErrors = true
+	}
+}
+
+func NewClient(ctx context.Context, conn io.ReadWriteCloser, opts ...Option) IrcClient {
 	cli := &client{
 		conn:      conn,
 		inputChan: make(chan parser.Message),
@@ -52,6 +86,9 @@ func NewClient(ctx context.Context, conn io.ReadWriteCloser) IrcClient {
 		scanner:   parser.NewScanner(conn),
 	}
 	cli.ctx, cli.cancelFunc = context.WithCancel(ctx)
+	for _, opt := range opts {
+		opt(cli)
+	}
 
 	return cli
 }
@@ -59,23 +96,87 @@ func NewClient(ctx context.Context, conn io.ReadWriteCloser) IrcClient {
 // TODO: Pass ctx?
 func (cli *client) ConsumeMessages() {
 	defer cli.cleanup()
+	if cli.keepAlive != nil {
+		go cli.runKeepAlive()
+	}
 	cli.readInput()
 }
 
 func (cli *client) Send(messages ...IrcMessage) error {
-	for _, msg := range messages {
+	return cli.SendContext(cli.ctx, messages...)
+}
+
+// SendContext validates every message before sending any, so an invalid message can't leave a batch half sent
+func (cli *client) SendContext(ctx context.Context, messages ...IrcMessage) error {
+	lines, err := cli.lines(messages)
+	if err != nil {
+		return err
+	}
+	for _, bs := range lines {
+		if cli.limiter != nil {
+			if err := cli.limiter.waitLine(ctx, bs); err != nil {
+				return err
+			}
+		}
 		// TODO: Retry?
-		if _, err := cli.conn.Write(append(msg.Bytes(), '\r', '\n')); err != nil {
+		if _, err := cli.conn.Write(append(bs, '\r', '\n')); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// lines serializes & validates the messages, splitting long PRIVMSGs if enabled
+func (cli *client) lines(messages []IrcMessage) ([][]byte, error) {
+	lines := make([][]byte, 0, len(messages))
+	for _, msg := range messages {
+		line, err := marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		split := [][]byte{line}
+		if cli.splitMessages {
+			split = splitPrivateMessage(split[0])
+		}
+		for _, bs := range split {
+			if err := ValidateLine(bs); err != nil {
+				return nil, fmt.Errorf("invalid %v message: %w", commandOf(bs), err)
+			}
+		}
+		lines = append(lines, split...)
+	}
+	return lines, nil
+}
+
+// marshal serializes the message, using MarshalText where it's implemented so messages such as parser.Message can reject params which would change the meaning of the line
+func marshal(msg IrcMessage) ([]byte, error) {
+	marshaler, ok := msg.(encoding.TextMarshaler)
+	if !ok {
+		return msg.Bytes(), nil
+	}
+	line, err := marshaler.MarshalText()
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	return line, nil
+}
+
 func (cli *client) Err() error {
+	cli.errMux.Lock()
+	defer cli.errMux.Unlock()
 	return cli.err
 }
 
+// fail closes the client, recording why it was closed
+func (cli *client) fail(err error) {
+	cli.errMux.Lock()
+	if cli.err == nil {
+		cli.err = err
+	}
+	cli.errMux.Unlock()
+	cli.cancelFunc()
+}
+
 func (cli *client) Done() <-chan struct{} {
 	return cli.done
 }
@@ -114,40 +215,74 @@ type messageError struct {
 	Error   error
 }
 
+// scan reads every line from the connection in a single goroutine until it fails or the client closes
+// Scan is used over ScanInto as each message is handed to consumers, so it can't be reused for the next line
 func (cli *client) scan() <-chan messageError {
 	msgChan := make(chan messageError)
 	go func() {
 		defer close(msgChan)
-		message, err := cli.scanner.Scan()
-		msgChan <- messageError{message, err}
+		for {
+			message, err := cli.scanner.Scan()
+			// The next line is read as soon as this one is sent, so the read time has to be taken now
+			if err == nil && cli.readTime != nil {
+				stampTime(message, cli.readTime())
+			}
+			select {
+			case msgChan <- messageError{message, err}:
+			case <-cli.ctx.Done():
+				return
+			}
+			if err != nil && !skippable(err) {
+				return
+			}
+		}
 	}()
 	return msgChan
 }
 
 // Scans the IRC messages and writes them to the input channel
 func (cli *client) readInput() {
+	messages := cli.scan()
 	for {
 		select {
 		case <-cli.ctx.Done():
 			return
-		case message := <-cli.scan():
+		case message := <-messages:
 			err := message.Error
 			if err != nil {
-				if errors.Is(err, io.EOF) {
-					cli.err = err
-					cli.cancelFunc()
+				// Anything other than a bad line means the connection is dead, so reading it again would spin
+				if !skippable(err) {
+					cli.fail(err)
 					return
 				}
 				cli.error(err)
 				continue
 			}
-			msg := *message.Message
-			cli.inputChan <- msg
+			if cli.keepAlive != nil && cli.keepAlive.received(message.Message) {
+				continue
+			}
+			cli.inputChan <- *message.Message
 		}
 	}
 }
 
+// skippable returns whether the error is from a single malformed line, rather than from the connection
+func skippable(err error) bool {
+	return errors.Is(err, parser.ErrEmptyMessage) ||
+		errors.Is(err, parser.ErrNoCommand) ||
+		errors.Is(err, parser.ErrNoPrefix) ||
+		errors.Is(err, parser.ErrTooLong) ||
+		errors.Is(err, parser.ErrTagsTooLong)
+}
+
 func (cli *client) error(err error) {
+	if err != nil && cli.blockingErrors {
+		select {
+		case cli.errorChan <- err:
+		case <-cli.ctx.Done():
+		}
+		return
+	}
 	if err != nil && !cli.Closed() {
 		select {
 		case cli.errorChan <- err:
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+)
+
+// Dialer opens a new connection to an IRC server
+type Dialer func(ctx context.Context) (io.ReadWriteCloser, error)
+
+var ErrNoCertificates = errors.New("no certificates found in CA bundle")
+
+// TCPDialer creates a Dialer which connects to the address over plain TCP
+func TCPDialer(address string) Dialer {
+	return func(ctx context.Context) (io.ReadWriteCloser, error) {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to Dial TCP %w", err)
+		}
+		return conn, nil
+	}
+}
+
+// TLSDialer creates a Dialer which connects to the address over TLS
+// The server name is taken from the address if it isn't set in the config
+func TLSDialer(address string, config *tls.Config) Dialer {
+	return func(ctx context.Context) (io.ReadWriteCloser, error) {
+		d := tls.Dialer{Config: config}
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to Dial TLS %w", err)
+		}
+		return conn, nil
+	}
+}
+
+// NewTLSConfig builds a TLS config trusting the CA bundle & presenting the client certificate
+// caFile, certFile & keyFile are all optional, the system roots are used without a CA bundle
+func NewTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(caFile) > 0 {
+		bs, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(bs) {
+			return nil, ErrNoCertificates
+		}
+	}
+	if len(certFile) > 0 || len(keyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+)
+
+// keepAliveToken is sent in every keepalive PING so the PONG can be told apart from other PONGs
+const keepAliveToken = "go-irc-kafka-keepalive"
+
+var ErrPingTimeout = errors.New("no data received from server before ping timeout")
+
+type (
+	keepAlive struct {
+		interval time.Duration
+		timeout  time.Duration
+		// lastRead, pingSent & latency are atomically accessed, the times are unix nanoseconds
+		lastRead int64
+		pingSent int64
+		latency  int64
+	}
+
+	pingMessage struct{}
+)
+
+func (pingMessage) Bytes() []byte {
+	return []byte(fmt.Sprintf("%v :%v", irc.Ping, keepAliveToken))
+}
+
+// WithKeepAlive sends a PING every interval, closing the connection with ErrPingTimeout if nothing has been read within the timeout
+// A zero interval disables the keepalive, and a zero timeout defaults to twice the interval
+func WithKeepAlive(interval, timeout time.Duration) Option {
+	return func(cli *client) {
+		if interval <= 0 {
+			return
+		}
+		// Every tick would time out otherwise
+		if timeout <= 0 {
+			timeout = 2 * interval
+		}
+		cli.keepAlive = &keepAlive{
+			interval: interval,
+			timeout:  timeout,
+		}
+	}
+}
+
+// Latency is the round trip time of the last keepalive PING
+func (cli *client) Latency() time.Duration {
+	if cli.keepAlive == nil {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&cli.keepAlive.latency))
+}
+
+// runKeepAlive periodically pings the server until the client closes, failing the client once the connection is stale
+func (cli *client) runKeepAlive() {
+	ka := cli.keepAlive
+	atomic.StoreInt64(&ka.lastRead, time.Now().UnixNano())
+	ticker := time.NewTicker(ka.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cli.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if now.Sub(time.Unix(0, atomic.LoadInt64(&ka.lastRead))) > ka.timeout {
+				cli.fail(ErrPingTimeout)
+				return
+			}
+			atomic.StoreInt64(&ka.pingSent, now.UnixNano())
+			if err := cli.Send(pingMessage{}); err != nil {
+				cli.error(fmt.Errorf("failed to send keepalive PING: %w", err))
+			}
+		}
+	}
+}
+
+// received marks the connection as alive, returning true if the message is the PONG to our keepalive
+func (ka *keepAlive) received(message *parser.Message) bool {
+	now := time.Now().UnixNano()
+	atomic.StoreInt64(&ka.lastRead, now)
+	if message.Command != irc.Pong || len(message.Params) == 0 || message.Params[len(message.Params)-1] != keepAliveToken {
+		return false
+	}
+	if sent := atomic.LoadInt64(&ka.pingSent); sent > 0 {
+		atomic.StoreInt64(&ka.latency, now-sent)
+	}
+	return true
+}
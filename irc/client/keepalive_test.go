@@ -0,0 +1,71 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepAlive_Latency(t *testing.T) {
+	conn := MakeMockConn()
+	ircClient := NewClient(context.Background(), conn, WithKeepAlive(10*time.Millisecond, time.Second))
+	defer ircClient.Close()
+	go ircClient.ConsumeMessages()
+	go consumeInput(ircClient)
+
+	line, err := bufio.NewReader(conn.ClientReader).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "PING :"+keepAliveToken+"\r\n", line)
+	_, _ = io.WriteString(conn.ClientWriter, ":tmi.twitch.tv PONG tmi.twitch.tv :"+keepAliveToken+"\r\n")
+
+	assert.Eventually(t, func() bool {
+		return ircClient.Latency() > 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestKeepAlive_Timeout(t *testing.T) {
+	conn := MakeMockConn()
+	ircClient := NewClient(context.Background(), conn, WithKeepAlive(5*time.Millisecond, 20*time.Millisecond))
+	defer ircClient.Close()
+	go ircClient.ConsumeMessages()
+	go consumeErrors(ircClient)
+	// Swallow the PINGs without ever replying
+	go func() { _, _ = io.Copy(io.Discard, conn.ClientReader) }()
+
+	select {
+	case <-ircClient.Done():
+		assert.ErrorIs(t, ircClient.Err(), ErrPingTimeout)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "Client didn't close after ping timeout")
+	}
+}
+
+func TestKeepAlive_DefaultTimeout(t *testing.T) {
+	conn := MakeMockConn()
+	ircClient := NewClient(context.Background(), conn, WithKeepAlive(5*time.Millisecond, 0))
+	defer ircClient.Close()
+	go ircClient.ConsumeMessages()
+	go consumeInput(ircClient)
+	assert.Equal(t, 10*time.Millisecond, ircClient.(*client).keepAlive.timeout)
+
+	// Replying to every PING keeps the connection open
+	go func() {
+		reader := bufio.NewReader(conn.ClientReader)
+		for {
+			if _, err := reader.ReadString('\n'); err != nil {
+				return
+			}
+			_, _ = io.WriteString(conn.ClientWriter, ":tmi.twitch.tv PONG tmi.twitch.tv :"+keepAliveToken+"\r\n")
+		}
+	}()
+	select {
+	case <-ircClient.Done():
+		assert.Fail(t, "Client closed with a zero timeout", ircClient.Err())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc"
+)
+
+type (
+	// Limit is the amount of messages allowed to be sent within a window
+	Limit struct {
+		Count int
+		Per   time.Duration
+	}
+
+	// RateLimiter holds a token bucket per command, commands without a bucket are never limited
+	// PRIVMSGs to channels where the bot is a moderator can use a separate bucket with a higher limit
+	RateLimiter struct {
+		buckets map[string]*bucket
+		// moderated is the channels the bot is a moderator in, without the #
+		moderated    map[string]struct{}
+		moderatedMux sync.Mutex
+	}
+
+	bucket struct {
+		limit   Limit
+		mux     sync.Mutex
+		tokens  float64
+		last    time.Time
+		waiting int64
+	}
+)
+
+// ModeratorMessages is the bucket for PRIVMSGs to channels where the bot is a moderator or broadcaster
+const ModeratorMessages = "PRIVMSG moderator"
+
+// NewRateLimiter creates a RateLimiter with a bucket for each command in limits, which can include ModeratorMessages
+func NewRateLimiter(limits map[string]Limit) *RateLimiter {
+	l := &RateLimiter{
+		buckets:   make(map[string]*bucket, len(limits)),
+		moderated: make(map[string]struct{}),
+	}
+	for command, limit := range limits {
+		l.buckets[command] = newBucket(limit)
+	}
+	return l
+}
+
+// NewTwitchRateLimiter creates a RateLimiter for PRIVMSGs, PRIVMSGs in moderated channels and JOINs
+// https://dev.twitch.tv/docs/irc/guide#rate-limits
+func NewTwitchRateLimiter(messages, moderatorMessages, joins Limit) *RateLimiter {
+	return NewRateLimiter(map[string]Limit{
+		irc.PrivateMessage: messages,
+		ModeratorMessages:  moderatorMessages,
+		irc.Join:           joins,
+	})
+}
+
+// Wait blocks until the command is allowed to be sent or the context is done
+func (l *RateLimiter) Wait(ctx context.Context, command string) error {
+	b, ok := l.buckets[command]
+	if !ok {
+		return nil
+	}
+	return b.wait(ctx)
+}
+
+// waitLine waits for the bucket of the raw IRC line, PRIVMSGs to moderated channels use the ModeratorMessages bucket
+func (l *RateLimiter) waitLine(ctx context.Context, line []byte) error {
+	command := commandOf(line)
+	if command == irc.PrivateMessage && l.IsModerator(targetOf(line)) {
+		if _, ok := l.buckets[ModeratorMessages]; ok {
+			command = ModeratorMessages
+		}
+	}
+	return l.Wait(ctx, command)
+}
+
+// SetModerator records whether the bot is a moderator in the channel, such as from the mod tag of a USERSTATE
+func (l *RateLimiter) SetModerator(channel string, moderator bool) {
+	channel = strings.ToLower(strings.TrimPrefix(channel, "#"))
+	l.moderatedMux.Lock()
+	defer l.moderatedMux.Unlock()
+	if moderator {
+		l.moderated[channel] = struct{}{}
+	} else {
+		delete(l.moderated, channel)
+	}
+}
+
+// IsModerator returns whether the bot is a moderator in the channel
+func (l *RateLimiter) IsModerator(channel string) bool {
+	channel = strings.ToLower(strings.TrimPrefix(channel, "#"))
+	l.moderatedMux.Lock()
+	defer l.moderatedMux.Unlock()
+	_, ok := l.moderated[channel]
+	return ok
+}
+
+// QueueDepth is the amount of senders currently blocked waiting on the bucket, either a command or ModeratorMessages
+func (l *RateLimiter) QueueDepth(bucket string) int {
+	b, ok := l.buckets[bucket]
+	if !ok {
+		return 0
+	}
+	return int(atomic.LoadInt64(&b.waiting))
+}
+
+// QueueDepths is the QueueDepth of every bucket
+func (l *RateLimiter) QueueDepths() map[string]int {
+	depths := make(map[string]int, len(l.buckets))
+	for name := range l.buckets {
+		depths[name] = l.QueueDepth(name)
+	}
+	return depths
+}
+
+func newBucket(limit Limit) *bucket {
+	return &bucket{
+		limit:  limit,
+		tokens: float64(limit.Count),
+		last:   time.Now(),
+	}
+}
+
+func (b *bucket) wait(ctx context.Context) error {
+	atomic.AddInt64(&b.waiting, 1)
+	defer atomic.AddInt64(&b.waiting, -1)
+	for {
+		wait := b.take()
+		if wait == 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// take takes a token if there is one available, otherwise returns how long until the next one
+func (b *bucket) take() time.Duration {
+	if b.limit.Count <= 0 {
+		return 0
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	now := time.Now()
+	perToken := float64(b.limit.Per) / float64(b.limit.Count)
+	b.tokens += float64(now.Sub(b.last)) / perToken
+	if b.tokens > float64(b.limit.Count) {
+		b.tokens = float64(b.limit.Count)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * perToken)
+}
+
+// targetOf finds the first param of a raw IRC line, such as the channel of a PRIVMSG
+func targetOf(line []byte) string {
+	_, params := splitCommand(line)
+	if i := bytes.IndexByte(params, ' '); i >= 0 {
+		params = params[:i]
+	}
+	return string(params)
+}
+
+// commandOf finds the command of a raw IRC line, skipping past any tags or prefix
+func commandOf(line []byte) string {
+	command, _ := splitCommand(line)
+	return command
+}
+
+// splitCommand splits a raw IRC line into the command & the params after it, skipping past any tags or prefix
+func splitCommand(line []byte) (string, []byte) {
+	for len(line) > 0 && (line[0] == '@' || line[0] == ':') {
+		i := bytes.IndexByte(line, ' ')
+		if i < 0 {
+			return "", nil
+		}
+		line = bytes.TrimLeft(line[i:], " ")
+	}
+	i := bytes.IndexByte(line, ' ')
+	if i < 0 {
+		return string(line), nil
+	}
+	return string(line[:i]), bytes.TrimLeft(line[i:], " ")
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	t.Run("Burst", func(t *testing.T) {
+		limiter := NewTwitchRateLimiter(Limit{Count: 3, Per: time.Hour}, Limit{}, Limit{})
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, limiter.Wait(context.Background(), "PRIVMSG"))
+		}
+	})
+
+	t.Run("Refills", func(t *testing.T) {
+		limiter := NewTwitchRateLimiter(Limit{}, Limit{}, Limit{Count: 1, Per: 50 * time.Millisecond})
+		start := time.Now()
+		require.NoError(t, limiter.Wait(context.Background(), "JOIN"))
+		require.NoError(t, limiter.Wait(context.Background(), "JOIN"))
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(40*time.Millisecond))
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		limiter := NewTwitchRateLimiter(Limit{Count: 1, Per: time.Hour}, Limit{}, Limit{})
+		require.NoError(t, limiter.Wait(context.Background(), "PRIVMSG"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errs := make(chan error)
+		go func() { errs <- limiter.Wait(ctx, "PRIVMSG") }()
+		assert.Eventually(t, func() bool {
+			return limiter.QueueDepth("PRIVMSG") == 1
+		}, time.Second, time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-errs, context.Canceled)
+		assert.Equal(t, 0, limiter.QueueDepth("PRIVMSG"))
+	})
+
+	t.Run("Unlimited command", func(t *testing.T) {
+		limiter := NewTwitchRateLimiter(Limit{Count: 1, Per: time.Hour}, Limit{}, Limit{})
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, limiter.Wait(context.Background(), "PONG"))
+		}
+	})
+
+	t.Run("Moderator", func(t *testing.T) {
+		limiter := NewTwitchRateLimiter(Limit{Count: 1, Per: time.Hour}, Limit{Count: 3, Per: time.Hour}, Limit{})
+		limiter.SetModerator("#Moderated", true)
+		assert.True(t, limiter.IsModerator("moderated"))
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, limiter.waitLine(context.Background(), []byte("@a=b PRIVMSG #moderated :hi")))
+		}
+		assert.NoError(t, limiter.waitLine(context.Background(), []byte("PRIVMSG #other :hi")))
+
+		// Both buckets are empty
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, limiter.waitLine(ctx, []byte("PRIVMSG #moderated :hi")), context.DeadlineExceeded)
+		assert.ErrorIs(t, limiter.waitLine(ctx, []byte("PRIVMSG #other :hi")), context.DeadlineExceeded)
+
+		limiter.SetModerator("moderated", false)
+		assert.False(t, limiter.IsModerator("moderated"))
+	})
+}
+
+func TestRateLimiter_QueueDepths(t *testing.T) {
+	limiter := NewTwitchRateLimiter(Limit{Count: 1, Per: time.Hour}, Limit{Count: 1, Per: time.Hour}, Limit{})
+	limiter.SetModerator("moderated", true)
+	require.NoError(t, limiter.waitLine(context.Background(), []byte("PRIVMSG #moderated :hi")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = limiter.waitLine(ctx, []byte("PRIVMSG #moderated :hi")) }()
+	assert.Eventually(t, func() bool {
+		return limiter.QueueDepth(ModeratorMessages) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, map[string]int{"PRIVMSG": 0, ModeratorMessages: 1, "JOIN": 0}, limiter.QueueDepths())
+}
+
+func Test_commandOf(t *testing.T) {
+	tests := map[string]string{
+		"PRIVMSG #channel :hello":                    "PRIVMSG",
+		"@reply-parent-msg-id=1 PRIVMSG #channel :a": "PRIVMSG",
+		":bot!bot@bot JOIN #channel":                 "JOIN",
+		"@a=b :prefix JOIN #channel":                 "JOIN",
+		"RECONNECT":                                  "RECONNECT",
+		"@a=b":                                       "",
+	}
+	for line, expected := range tests {
+		assert.Equal(t, expected, commandOf([]byte(line)), line)
+	}
+}
+
+func Test_targetOf(t *testing.T) {
+	tests := map[string]string{
+		"PRIVMSG #channel :hello":                 "#channel",
+		"@a=PRIVMSG :prefix PRIVMSG #channel :hi": "#channel",
+		"RECONNECT": "",
+	}
+	for line, expected := range tests {
+		assert.Equal(t, expected, targetOf([]byte(line)), line)
+	}
+}
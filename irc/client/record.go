@@ -0,0 +1,105 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+)
+
+// Recordings have a line for every read from the connection, the time it was read followed by the quoted bytes
+// 2022-01-20T21:55:56.806Z ":tmi.twitch.tv PING :tmi.twitch.tv\r\n"
+
+var ErrInvalidRecord = errors.New("invalid record")
+
+type (
+	// Recorder writes the raw bytes read from IRC with the time they were read, it can be shared between clients
+	Recorder struct {
+		mux sync.Mutex
+		w   io.Writer
+		now func() time.Time
+	}
+
+	// Record is a single read from a recorded connection
+	Record struct {
+		Time time.Time
+		Data []byte
+	}
+
+	// recordingReader records everything read through it
+	recordingReader struct {
+		io.Reader
+		recorder *Recorder
+		onError  func(error)
+	}
+)
+
+// NewRecorder creates a Recorder which writes a record per read, use a RotatingFile to limit the size of recordings
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		w:   w,
+		now: time.Now,
+	}
+}
+
+// WithRecorder records the exact bytes read from the connection before they're parsed
+func WithRecorder(recorder *Recorder) Option {
+	return func(cli *client) {
+		cli.scanner = parser.NewScanner(&recordingReader{
+			Reader:   cli.conn,
+			recorder: recorder,
+			onError:  cli.error,
+		})
+	}
+}
+
+// Record writes the data as a single record, timestamped with the current time
+func (r *Recorder) Record(data []byte) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	line, err := Record{Time: r.now(), Data: data}.MarshalText()
+	if err != nil {
+		return err
+	}
+	_, err = r.w.Write(append(line, '\n'))
+	return err
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		// Failing to record shouldn't drop the connection
+		if recordErr := r.recorder.Record(p[:n]); recordErr != nil {
+			r.onError(fmt.Errorf("failed to record input: %w", recordErr))
+		}
+	}
+	return n, err
+}
+
+func (r Record) MarshalText() ([]byte, error) {
+	line := r.Time.UTC().AppendFormat(nil, time.RFC3339Nano)
+	line = append(line, ' ')
+	return strconv.AppendQuote(line, string(r.Data)), nil
+}
+
+func (r *Record) UnmarshalText(text []byte) error {
+	i := bytes.IndexByte(text, ' ')
+	if i < 0 {
+		return fmt.Errorf("%w: no data", ErrInvalidRecord)
+	}
+	t, err := time.Parse(time.RFC3339Nano, string(text[:i]))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+	data, err := strconv.Unquote(string(text[i+1:]))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+	r.Time, r.Data = t, []byte(data)
+	return nil
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord_MarshalText(t *testing.T) {
+	record := Record{
+		Time: time.Date(2022, 1, 20, 21, 55, 56, 806000000, time.UTC),
+		Data: []byte(":tmi.twitch.tv PING :tmi.twitch.tv\r\n\xff"),
+	}
+	text, err := record.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, `2022-01-20T21:55:56.806Z ":tmi.twitch.tv PING :tmi.twitch.tv\r\n\xff"`, string(text))
+
+	var parsed Record
+	require.NoError(t, parsed.UnmarshalText(text))
+	assert.True(t, record.Time.Equal(parsed.Time))
+	assert.Equal(t, record.Data, parsed.Data)
+
+	t.Run("Invalid", func(t *testing.T) {
+		var r Record
+		assert.ErrorIs(t, r.UnmarshalText([]byte("2022-01-20T21:55:56.806Z")), ErrInvalidRecord)
+		assert.ErrorIs(t, r.UnmarshalText([]byte(`yesterday "PING"`)), ErrInvalidRecord)
+		assert.ErrorIs(t, r.UnmarshalText([]byte(`2022-01-20T21:55:56.806Z PING`)), ErrInvalidRecord)
+	})
+}
+
+func TestWithRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	recorder.now = func() time.Time {
+		return time.Date(2022, 1, 20, 21, 55, 56, 0, time.UTC)
+	}
+	conn := MakeMockConn()
+	cli := NewClient(context.Background(), conn, WithRecorder(recorder))
+	defer cli.Close()
+	go cli.ConsumeMessages()
+
+	_, _ = io.WriteString(conn.ClientWriter, "PING :tmi.twitch.tv\r\n")
+	select {
+	case msg := <-cli.Input():
+		assert.Equal(t, "PING", msg.Command)
+	case <-time.After(time.Second):
+		t.Fatal("didn't receive message")
+	}
+	assert.Equal(t, "2022-01-20T21:55:56Z \"PING :tmi.twitch.tv\\r\\n\"\n", buf.String())
+}
+
+func TestRotatingFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	f, err := NewRotatingFile(fs, "irc.log", 10, 2)
+	require.NoError(t, err)
+
+	for _, s := range []string{"aaaaaa", "bbbb", "cccc", "dddddddddddd", "ee"} {
+		_, err := io.WriteString(f, s)
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	read := func(path string) string {
+		bs, err := afero.ReadFile(fs, path)
+		require.NoError(t, err)
+		return string(bs)
+	}
+	assert.Equal(t, "ee", read("irc.log"))
+	assert.Equal(t, "dddddddddddd", read("irc.log.1"))
+	assert.Equal(t, "cccc", read("irc.log.2"))
+	exists, _ := afero.Exists(fs, "irc.log.3")
+	assert.False(t, exists)
+
+	t.Run("Appends to existing file", func(t *testing.T) {
+		f, err := NewRotatingFile(fs, "irc.log", 10, 2)
+		require.NoError(t, err)
+		_, _ = io.WriteString(f, "ff")
+		_, _ = io.WriteString(f, "gggggggg")
+		require.NoError(t, f.Close())
+		assert.Equal(t, "gggggggg", read("irc.log"))
+		assert.Equal(t, "eeff", read("irc.log.1"))
+	})
+
+	t.Run("No old files", func(t *testing.T) {
+		f, err := NewRotatingFile(fs, "other.log", 4, 0)
+		require.NoError(t, err)
+		_, _ = io.WriteString(f, "aaaa")
+		_, _ = io.WriteString(f, "bb")
+		require.NoError(t, f.Close())
+		assert.Equal(t, "bb", read("other.log"))
+		exists, _ := afero.Exists(fs, "other.log.1")
+		assert.False(t, exists)
+	})
+}
+
+func TestReplayConn(t *testing.T) {
+	recording := `2022-01-20T21:55:56Z "PING :tmi.twitch.tv\r\n:nick!user@host JOIN"
+2022-01-20T21:55:56.2Z " #channel\r\n"
+`
+	t.Run("Original speed", func(t *testing.T) {
+		conn := NewReplayConn(bytes.NewBufferString(recording), 1, nil)
+		start := time.Now()
+		bs, err := io.ReadAll(conn)
+		require.NoError(t, err)
+		assert.Equal(t, "PING :tmi.twitch.tv\r\n:nick!user@host JOIN #channel\r\n", string(bs))
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("Scanner", func(t *testing.T) {
+		scanner := parser.NewScanner(NewReplayConn(bytes.NewBufferString(recording), 0, nil))
+		msg, err := scanner.Scan()
+		require.NoError(t, err)
+		assert.Equal(t, "PING", msg.Command)
+		msg, err = scanner.Scan()
+		require.NoError(t, err)
+		assert.Equal(t, parser.Params{"#channel"}, msg.Params)
+		_, err = scanner.Scan()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("Close while waiting", func(t *testing.T) {
+		conn := NewReplayConn(bytes.NewBufferString(`2022-01-20T21:55:56Z "a"
+2022-01-20T22:55:56Z "b"
+`), 1, nil)
+		buf := make([]byte, 8)
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "a", string(buf[:n]))
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = conn.Close()
+		}()
+		_, err = conn.Read(buf)
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("Invalid record", func(t *testing.T) {
+		var errs []error
+		conn := NewReplayConn(bytes.NewBufferString("PING\n"+recording), 0, func(err error) {
+			errs = append(errs, err)
+		})
+		bs, err := io.ReadAll(conn)
+		require.NoError(t, err)
+		// The invalid record is skipped, but the rest are still replayed
+		assert.Equal(t, "PING :tmi.twitch.tv\r\n:nick!user@host JOIN #channel\r\n", string(bs))
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], ErrInvalidRecord)
+	})
+
+	t.Run("Read time", func(t *testing.T) {
+		conn := NewReplayConn(bytes.NewBufferString(recording), 0, nil)
+		assert.True(t, conn.ReadTime().IsZero())
+		buf := make([]byte, 64)
+		_, err := conn.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2022, 1, 20, 21, 55, 56, 0, time.UTC), conn.ReadTime())
+		_, err = conn.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2022, 1, 20, 21, 55, 56, 200*int(time.Millisecond), time.UTC), conn.ReadTime())
+	})
+
+	t.Run("Client", func(t *testing.T) {
+		conn := NewReplayConn(bytes.NewBufferString(`2022-01-20T21:55:56Z "@time=2022-01-20T21:00:00Z PING\r\n"
+2022-01-20T21:55:57Z ":nick!user@host JOIN #channel\r\n"
+`), 0, nil)
+		cli := NewClient(context.Background(), conn, WithReadTime(conn.ReadTime))
+		defer cli.Close()
+		go cli.ConsumeMessages()
+		// The server-time is kept if it was sent, otherwise it's the time it was recorded
+		var times []string
+		for msg := range cli.Input() {
+			times = append(times, msg.Tags["time"])
+		}
+		assert.Equal(t, []string{"2022-01-20T21:00:00Z", "2022-01-20T21:55:57Z"}, times)
+	})
+}
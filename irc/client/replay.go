@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+)
+
+// ReplayConn reads a recording back at the pace it was recorded, anything written is discarded as there's no server
+type ReplayConn struct {
+	records *bufio.Reader
+	// onInvalid is called with each invalid record, which is skipped
+	onInvalid func(error)
+	// speed is how many times faster than recorded to replay, 0 doesn't wait between records
+	speed   float64
+	pending []byte
+	// first is the time of the first record & start is when it was replayed
+	first time.Time
+	start time.Time
+
+	// readMux guards read, the time of the last record read
+	readMux sync.Mutex
+	read    time.Time
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewReplayConn creates a connection which replays a recording made by a Recorder, reaching EOF at the end of the recording
+// speed is how many times faster than recorded to replay, 0 replays as fast as it's read
+// Invalid records are skipped & passed to onInvalid, as returning them from Read would close the client
+func NewReplayConn(recording io.Reader, speed float64, onInvalid func(error)) *ReplayConn {
+	return &ReplayConn{
+		records:   bufio.NewReader(recording),
+		onInvalid: onInvalid,
+		speed:     speed,
+		closed:    make(chan struct{}),
+	}
+}
+
+// WithReadTime sets the server-time tag of messages without one to the time they were read, such as ReplayConn.ReadTime
+func WithReadTime(readTime func() time.Time) Option {
+	return func(cli *client) {
+		cli.readTime = readTime
+	}
+}
+
+func (c *ReplayConn) Read(p []byte) (int, error) {
+	for len(c.pending) == 0 {
+		record, err := c.next()
+		if errors.Is(err, ErrInvalidRecord) {
+			c.onInvalid(err)
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if err := c.wait(record.Time); err != nil {
+			return 0, err
+		}
+		c.readMux.Lock()
+		c.pending, c.read = record.Data, record.Time
+		c.readMux.Unlock()
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// ReadTime is when the last record read was recorded, which is when the last complete line was received
+func (c *ReplayConn) ReadTime() time.Time {
+	c.readMux.Lock()
+	defer c.readMux.Unlock()
+	return c.read
+}
+
+func (c *ReplayConn) Write(p []byte) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, io.ErrClosedPipe
+	default:
+		return len(p), nil
+	}
+}
+
+func (c *ReplayConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	return nil
+}
+
+// next reads the next record, returning io.EOF at the end of the recording
+func (c *ReplayConn) next() (Record, error) {
+	var record Record
+	line, err := c.records.ReadBytes('\n')
+	if errors.Is(err, io.EOF) && len(line) > 0 {
+		// The last record may not have a newline if the recording was cut short
+		err = nil
+	}
+	if err != nil {
+		return record, err
+	}
+	if err := record.UnmarshalText(bytes.TrimSuffix(line, []byte{'\n'})); err != nil {
+		return record, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return record, nil
+}
+
+// wait sleeps until the record is due relative to the first record, returning io.EOF if the connection is closed first
+func (c *ReplayConn) wait(t time.Time) error {
+	if c.first.IsZero() {
+		c.first, c.start = t, time.Now()
+	}
+	var due time.Duration
+	if c.speed > 0 {
+		due = time.Duration(float64(t.Sub(c.first))/c.speed) - time.Since(c.start)
+	}
+	if due <= 0 {
+		select {
+		case <-c.closed:
+			return io.EOF
+		default:
+			return nil
+		}
+	}
+	timer := time.NewTimer(due)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.closed:
+		return io.EOF
+	}
+}
+
+// stampTime sets the server-time tag to t if the message doesn't have one
+func stampTime(message *parser.Message, t time.Time) {
+	if _, ok := message.Tags["time"]; ok || t.IsZero() {
+		return
+	}
+	if message.Tags == nil {
+		message.Tags = parser.Tags{}
+	}
+	message.Tags["time"] = t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// RotatingFile appends to a file, moving it to path.1 once it reaches the max size & keeping up to maxFiles old files
+type RotatingFile struct {
+	fs       afero.Fs
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mux  sync.Mutex
+	file afero.File
+	size int64
+}
+
+// NewRotatingFile opens the file for appending, a maxSize of 0 never rotates
+func NewRotatingFile(fs afero.Fs, path string, maxSize int64, maxFiles int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		fs:       fs,
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes p to the current file, rotating first if it would go over the max size so p is never split between files
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) Close() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.file.Close()
+}
+
+func (f *RotatingFile) open() error {
+	file, err := f.fs.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %v: %w", f.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat %v: %w", f.path, err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts every old file up by one, dropping the oldest, then starts a new file
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close %v: %w", f.path, err)
+	}
+	if f.maxFiles == 0 {
+		if err := f.remove(f.path); err != nil {
+			return err
+		}
+		return f.open()
+	}
+	// Renaming over an existing file fails on Windows
+	if err := f.remove(f.backup(f.maxFiles)); err != nil {
+		return err
+	}
+	for i := f.maxFiles - 1; i >= 1; i-- {
+		if err := f.fs.Rename(f.backup(i), f.backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate %v: %w", f.backup(i), err)
+		}
+	}
+	if err := f.fs.Rename(f.path, f.backup(1)); err != nil {
+		return fmt.Errorf("failed to rotate %v: %w", f.path, err)
+	}
+	return f.open()
+}
+
+// backup is the name of the i-th old file
+func (f *RotatingFile) backup(i int) string {
+	return fmt.Sprintf("%v.%d", f.path, i)
+}
+
+func (f *RotatingFile) remove(path string) error {
+	if err := f.fs.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %v: %w", path, err)
+	}
+	return nil
+}
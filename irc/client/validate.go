@@ -0,0 +1,189 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ch629/go-irc-kafka/irc"
+)
+
+// https://ircv3.net/specs/extensions/message-tags.html#size-limit
+const (
+	// MaxLineLength is the most bytes a line can be, excluding tags & including the CRLF
+	MaxLineLength = 512
+	// MaxClientTagLength is the most bytes of tags a client can send, including the @ & trailing space
+	MaxClientTagLength = 4096
+)
+
+var (
+	ErrInvalidCharacter = errors.New("message contains CR, LF or NUL")
+	ErrLineTooLong      = errors.New("line is too long")
+	ErrTagsTooLong      = errors.New("tags are too long")
+	ErrInvalidTag       = errors.New("invalid tag key")
+	ErrInvalidCommand   = errors.New("invalid command")
+)
+
+// WithMessageSplitting splits PRIVMSGs which are too long across multiple lines, breaking on spaces where possible
+func WithMessageSplitting() Option {
+	return func(cli *client) {
+		cli.splitMessages = true
+	}
+}
+
+// ValidateLine checks the serialized message can be sent as a single line
+// Params can't be told apart once serialized, so messages implementing encoding.TextMarshaler check their own params when sent
+func ValidateLine(line []byte) error {
+	if i := bytes.IndexAny(line, "\r\n\x00"); i >= 0 {
+		return fmt.Errorf("%w at %d", ErrInvalidCharacter, i)
+	}
+	tags, body := splitTags(line)
+	if len(tags) > MaxClientTagLength {
+		return fmt.Errorf("%w: %d bytes", ErrTagsTooLong, len(tags))
+	}
+	if err := validateTags(tags); err != nil {
+		return err
+	}
+	// A malformed tag or prefix can leave part of it read as the command
+	if command := commandOf(body); !isCommand(command) {
+		return fmt.Errorf("%w: %q", ErrInvalidCommand, command)
+	}
+	// The CRLF counts towards the limit
+	if len(body)+2 > MaxLineLength {
+		return fmt.Errorf("%w: %d bytes", ErrLineTooLong, len(body)+2)
+	}
+	return nil
+}
+
+// validateTags checks every tag key follows the spec, so a key can't hide a space, ; or = which would change how the line is read
+func validateTags(tags []byte) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	for _, tag := range bytes.Split(bytes.TrimSuffix(tags[1:], []byte{' '}), []byte{';'}) {
+		key := tag
+		if i := bytes.IndexByte(tag, '='); i >= 0 {
+			key = tag[:i]
+		}
+		if !isTagKey(key) {
+			return fmt.Errorf("%w: %q", ErrInvalidTag, key)
+		}
+	}
+	return nil
+}
+
+// isTagKey returns whether the key is [+][vendor/]name, where the vendor is a host name & the name is letters, digits or -
+func isTagKey(key []byte) bool {
+	key = bytes.TrimPrefix(key, []byte{'+'})
+	if i := bytes.LastIndexByte(key, '/'); i >= 0 {
+		if !isTagKeyPart(key[:i], "-.") {
+			return false
+		}
+		key = key[i+1:]
+	}
+	return isTagKeyPart(key, "-")
+}
+
+func isTagKeyPart(part []byte, allowed string) bool {
+	if len(part) == 0 {
+		return false
+	}
+	for _, c := range part {
+		if !isLetter(c) && !('0' <= c && c <= '9') && !strings.ContainsRune(allowed, rune(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isCommand returns whether the command is all letters or a three digit numeric
+func isCommand(command string) bool {
+	if irc.IsNumeric(command) {
+		return true
+	}
+	for i := 0; i < len(command); i++ {
+		if !isLetter(command[i]) {
+			return false
+		}
+	}
+	return len(command) > 0
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// splitTags splits the tags, including the @ & trailing space, from the rest of the line
+func splitTags(line []byte) (tags, body []byte) {
+	if len(line) == 0 || line[0] != '@' {
+		return nil, line
+	}
+	i := bytes.IndexByte(line, ' ')
+	if i < 0 {
+		return line, nil
+	}
+	return line[:i+1], line[i+1:]
+}
+
+// splitPrivateMessage splits a PRIVMSG line which is too long into lines which fit, each with the same tags
+// Any other line is returned as is
+func splitPrivateMessage(line []byte) [][]byte {
+	tags, body := splitTags(line)
+	if len(body)+2 <= MaxLineLength || commandOf(body) != irc.PrivateMessage {
+		return [][]byte{line}
+	}
+	// [:prefix ]PRIVMSG <target> :<text>, the Scanner can't be used as the line is too long
+	head := 0
+	if body[0] == ':' {
+		head = bytes.IndexByte(body, ' ') + 1
+	}
+	head += len(irc.PrivateMessage) + 1
+	target := bytes.IndexByte(body[head:], ' ')
+	if target < 0 {
+		return [][]byte{line}
+	}
+	head += target + 1
+	text := string(bytes.TrimPrefix(body[head:], []byte(":")))
+	prefix := string(tags) + string(body[:head]) + ":"
+
+	max := MaxLineLength - 2 - (len(prefix) - len(tags))
+	// A target this long leaves no room for the text, so the line is left for ValidateLine to reject
+	if max < utf8.UTFMax {
+		return [][]byte{line}
+	}
+	chunks := splitText(text, max)
+	lines := make([][]byte, len(chunks))
+	for i, chunk := range chunks {
+		lines[i] = []byte(prefix + chunk)
+	}
+	return lines
+}
+
+// splitText splits the text into chunks of at most max bytes, breaking on the last space where possible
+// The text is returned as is if max can't fit a rune
+func splitText(text string, max int) []string {
+	if max < utf8.UTFMax {
+		return []string{text}
+	}
+	var chunks []string
+	for len(text) > max {
+		cut := strings.LastIndexByte(text[:max+1], ' ')
+		next := cut + 1
+		if cut <= 0 {
+			// No space to break on, so break the word without splitting a rune
+			cut = max
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				cut = max
+			}
+			next = cut
+		}
+		chunks = append(chunks, text[:cut])
+		text = text[next:]
+	}
+	return append(chunks, text)
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bufferConn records everything written to it
+type bufferConn struct {
+	bytes.Buffer
+}
+
+func (*bufferConn) Close() error {
+	return nil
+}
+
+func TestValidateLine(t *testing.T) {
+	assert.NoError(t, ValidateLine([]byte("PRIVMSG #channel :hello")))
+	assert.ErrorIs(t, ValidateLine([]byte("PRIVMSG #channel :hello\r\nJOIN #other")), ErrInvalidCharacter)
+	assert.ErrorIs(t, ValidateLine([]byte("PRIVMSG #channel :hello\nJOIN #other")), ErrInvalidCharacter)
+	assert.ErrorIs(t, ValidateLine([]byte("PRIVMSG #channel :hello\x00")), ErrInvalidCharacter)
+
+	// 510 bytes leaves room for the CRLF
+	assert.NoError(t, ValidateLine([]byte("PRIVMSG #c :"+strings.Repeat("a", 498))))
+	assert.ErrorIs(t, ValidateLine([]byte("PRIVMSG #c :"+strings.Repeat("a", 499))), ErrLineTooLong)
+
+	// Tags don't count towards the line length
+	tags := "@client-nonce=" + strings.Repeat("a", 100) + " "
+	assert.NoError(t, ValidateLine([]byte(tags+"PRIVMSG #c :"+strings.Repeat("a", 498))))
+	tags = "@client-nonce=" + strings.Repeat("a", MaxClientTagLength) + " "
+	assert.ErrorIs(t, ValidateLine([]byte(tags+"PRIVMSG #c :hi")), ErrTagsTooLong)
+
+	// Tag keys & the command must be well formed, so a tag can't change the command
+	assert.NoError(t, ValidateLine([]byte("@+example.com/key=a;reply-parent-msg-id=1;flag :nick!user@host PRIVMSG #c :hi")))
+	assert.NoError(t, ValidateLine([]byte("421 nick FOO :Unknown command")))
+	assert.ErrorIs(t, ValidateLine([]byte("@x :evil!e@e QUIT=y PRIVMSG #c hi")), ErrInvalidCommand)
+	assert.ErrorIs(t, ValidateLine([]byte("@a=b;=c PRIVMSG #c hi")), ErrInvalidTag)
+	assert.ErrorIs(t, ValidateLine([]byte("@a:b=c PRIVMSG #c hi")), ErrInvalidTag)
+	assert.ErrorIs(t, ValidateLine([]byte("@/key=c PRIVMSG #c hi")), ErrInvalidTag)
+	assert.ErrorIs(t, ValidateLine([]byte("@key=c")), ErrInvalidCommand)
+}
+
+func TestSplitText(t *testing.T) {
+	assert.Equal(t, []string{"hello"}, splitText("hello", 10))
+	assert.Equal(t, []string{"hello", "world"}, splitText("hello world", 10))
+	assert.Equal(t, []string{"hello", "world"}, splitText("hello world", 5))
+	assert.Equal(t, []string{"abcde", "fghij", "k"}, splitText("abcdefghijk", 5))
+	// Multi-byte runes aren't split
+	for _, chunk := range splitText(strings.Repeat("é", 10), 5) {
+		assert.True(t, utf8.ValidString(chunk))
+		assert.LessOrEqual(t, len(chunk), 5)
+	}
+	// Too small for a rune, so it can't be split
+	assert.Equal(t, []string{"hello"}, splitText("hello", 0))
+	assert.Equal(t, []string{"hello"}, splitText("hello", -5))
+}
+
+func TestClient_SendContext_Validation(t *testing.T) {
+	t.Run("Injection", func(t *testing.T) {
+		conn := &bufferConn{}
+		cli := NewClient(context.Background(), conn)
+		defer cli.Close()
+		err := cli.Send(&stringMessage{"JOIN #channel"}, &stringMessage{"PRIVMSG #channel :hi\r\nPART #channel"})
+		assert.ErrorIs(t, err, ErrInvalidCharacter)
+		// Nothing is sent if any message is invalid
+		assert.Empty(t, conn.String())
+	})
+
+	t.Run("Invalid param", func(t *testing.T) {
+		conn := &bufferConn{}
+		cli := NewClient(context.Background(), conn)
+		defer cli.Close()
+		// A space in the channel would otherwise be sent as an extra param
+		err := cli.Send(parser.Message{Command: "PRIVMSG", Params: parser.Params{"#channel extra", "hi"}})
+		assert.ErrorIs(t, err, parser.ErrInvalidParam)
+		assert.Empty(t, conn.String())
+	})
+
+	t.Run("Invalid tag key", func(t *testing.T) {
+		conn := &bufferConn{}
+		cli := NewClient(context.Background(), conn)
+		defer cli.Close()
+		// The key would otherwise turn the rest of the tag into a prefix & change the command to QUIT=y
+		err := cli.Send(parser.Message{Tags: parser.Tags{"x :evil!e@e QUIT": "y"}, Command: "PRIVMSG", Params: parser.Params{"#c", "hi"}})
+		assert.ErrorIs(t, err, parser.ErrInvalidTagKey)
+		assert.ErrorIs(t, cli.Send(&stringMessage{"@x :evil!e@e QUIT=y PRIVMSG #c hi"}), ErrInvalidCommand)
+		assert.ErrorIs(t, cli.Send(&stringMessage{"@a;b c=d PRIVMSG #c hi"}), ErrInvalidCommand)
+		assert.Empty(t, conn.String())
+	})
+
+	t.Run("Too long", func(t *testing.T) {
+		conn := &bufferConn{}
+		cli := NewClient(context.Background(), conn)
+		defer cli.Close()
+		assert.ErrorIs(t, cli.Send(&stringMessage{"PRIVMSG #channel :" + strings.Repeat("a", 600)}), ErrLineTooLong)
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		conn := &bufferConn{}
+		cli := NewClient(context.Background(), conn, WithMessageSplitting())
+		defer cli.Close()
+		text := strings.TrimSpace(strings.Repeat("word ", 200))
+		message := parser.Message{
+			Tags:    parser.Tags{"reply-parent-msg-id": "1"},
+			Command: "PRIVMSG",
+			Params:  parser.Params{"#channel", text},
+		}
+		require.NoError(t, cli.Send(message))
+
+		lines := strings.Split(strings.TrimSuffix(conn.String(), "\r\n"), "\r\n")
+		require.Len(t, lines, 3)
+		var words []string
+		for _, line := range lines {
+			assert.NoError(t, ValidateLine([]byte(line)))
+			var m parser.Message
+			require.NoError(t, m.UnmarshalText([]byte(line)))
+			assert.Equal(t, "1", m.Tags["reply-parent-msg-id"])
+			assert.Equal(t, "#channel", m.Params[0])
+			words = append(words, strings.Fields(m.Params[1])...)
+		}
+		assert.Equal(t, strings.Fields(text), words)
+	})
+
+	t.Run("Split with a long target", func(t *testing.T) {
+		conn := &bufferConn{}
+		cli := NewClient(context.Background(), conn, WithMessageSplitting())
+		defer cli.Close()
+		// The target leaves no room for any text, so the line can't be split
+		for _, target := range []string{strings.Repeat("a", 498), strings.Repeat("a", 500)} {
+			err := cli.Send(&stringMessage{"PRIVMSG #" + target + " :" + strings.Repeat("b", 100)})
+			assert.ErrorIs(t, err, ErrLineTooLong)
+		}
+		assert.Empty(t, conn.String())
+	})
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var crlf = []byte("\r\n")
+
+// wsConn adapts a WebSocket connection into a stream of IRC lines, with one IRC line per frame
+type wsConn struct {
+	conn     *websocket.Conn
+	writeMux sync.Mutex
+	// buf is the remainder of the last frame read
+	buf []byte
+	// err is the error which ended the connection, gorilla panics if a failed connection is read too many times
+	err error
+}
+
+// wsClosedError is a failed read, which is always terminal for a WebSocket so it ends the client like io.EOF
+type wsClosedError struct {
+	err error
+}
+
+// WebSocketDialer creates a Dialer which connects to the ws:// or wss:// URL
+// tlsConfig is optional & only used for wss://
+func WebSocketDialer(url string, tlsConfig *tls.Config) Dialer {
+	return func(ctx context.Context) (io.ReadWriteCloser, error) {
+		d := websocket.Dialer{
+			Proxy:            websocket.DefaultDialer.Proxy,
+			HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+			TLSClientConfig:  tlsConfig,
+		}
+		conn, _, err := d.DialContext(ctx, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to Dial WebSocket %w", err)
+		}
+		return NewWebSocketConn(conn), nil
+	}
+}
+
+// NewWebSocketConn wraps the WebSocket connection so it can be used by NewClient
+func NewWebSocketConn(conn *websocket.Conn) io.ReadWriteCloser {
+	return &wsConn{conn: conn}
+}
+
+// Read reads the frames as CRLF terminated IRC lines
+func (c *wsConn) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.err = io.EOF
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.err = &wsClosedError{err: err}
+			}
+			return 0, c.err
+		}
+		if len(data) > 0 && !bytes.HasSuffix(data, crlf) {
+			data = append(data, crlf...)
+		}
+		c.buf = data
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+func (e *wsClosedError) Error() string {
+	return fmt.Sprintf("websocket closed: %v", e.err)
+}
+
+func (e *wsClosedError) Unwrap() error {
+	return e.err
+}
+
+func (e *wsClosedError) Is(target error) bool {
+	return target == io.EOF
+}
+
+// Write sends each IRC line in p as its own frame
+func (c *wsConn) Write(p []byte) (int, error) {
+	c.writeMux.Lock()
+	defer c.writeMux.Unlock()
+	for _, line := range bytes.Split(p, crlf) {
+		if len(line) == 0 {
+			continue
+		}
+		if err := c.conn.WriteMessage(websocket.TextMessage, line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close sends a close frame before closing the underlying connection
+func (c *wsConn) Close() error {
+	c.writeMux.Lock()
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	c.writeMux.Unlock()
+	return c.conn.Close()
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocketDialer(t *testing.T) {
+	frames := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(rw, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Frames without a trailing CRLF should still be read as a full line
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(":tmi.twitch.tv 001 thewolfpack :Welcome, GLHF!"))
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			frames <- string(data)
+		}
+	}))
+	defer server.Close()
+
+	conn, err := WebSocketDialer("ws"+strings.TrimPrefix(server.URL, "http"), nil)(context.Background())
+	require.NoError(t, err)
+	ircClient := NewClient(context.Background(), conn)
+	defer ircClient.Close()
+	go ircClient.ConsumeMessages()
+
+	select {
+	case msg := <-ircClient.Input():
+		assert.Equal(t, parser.Message{
+			Tags:    map[string]string{},
+			Prefix:  "tmi.twitch.tv",
+			Command: "001",
+			Params: []string{
+				"thewolfpack",
+				"Welcome, GLHF!",
+			},
+		}, msg)
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "Timed out while getting input")
+	}
+
+	require.NoError(t, ircClient.Send(&stringMessage{"NICK bot"}, &stringMessage{"JOIN #channel"}))
+	for _, expected := range []string{"NICK bot", "JOIN #channel"} {
+		select {
+		case frame := <-frames:
+			assert.Equal(t, expected, frame)
+		case <-time.After(2 * time.Second):
+			require.Fail(t, "Timed out waiting for frame")
+		}
+	}
+}
+
+func TestWebSocketConn_Dropped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(rw, r, nil)
+		if err != nil {
+			return
+		}
+		// Drop the connection without a close frame
+		_ = conn.UnderlyingConn().Close()
+	}))
+	defer server.Close()
+
+	conn, err := WebSocketDialer("ws"+strings.TrimPrefix(server.URL, "http"), nil)(context.Background())
+	require.NoError(t, err)
+	ircClient := NewClient(context.Background(), conn)
+	defer ircClient.Close()
+	go ircClient.ConsumeMessages()
+
+	select {
+	case <-ircClient.Done():
+		assert.ErrorIs(t, ircClient.Err(), io.EOF)
+		var closeErr *websocket.CloseError
+		if assert.ErrorAs(t, ircClient.Err(), &closeErr) {
+			assert.Equal(t, websocket.CloseAbnormalClosure, closeErr.Code)
+		}
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "Client didn't close after the connection dropped")
+	}
+}
@@ -4,34 +4,32 @@ const (
 	// Inbound
 
 	Ping       = "PING"
-	EndOfMOTD  = "376"
 	RoomState  = "ROOMSTATE"
 	UserState  = "USERSTATE"
 	UserNotice = "USERNOTICE"
-	// ClearChat clears an entire user's chat
+	// ClearChat clears an entire user's chat, or the whole channel's chat without a user
 	ClearChat = "CLEARCHAT"
 	// ClearMessage clears one single message
 	ClearMessage = "CLEARMSG"
 	HostTarget   = "HOSTTARGET"
 	// Notice is received when room state has been updated or a channel is hosting another when initially joining
 	Notice = "NOTICE"
+	// Reconnect is sent by Twitch before the server goes down for maintenance
+	Reconnect = "RECONNECT"
 
 	// Outbound
 
 	Pong     = "PONG"
-	Join     = "JOIN"
-	Part     = "PART"
 	Password = "PASS"
 	Nickname = "NICK"
+	User     = "USER"
 
 	// Both
 
 	Capability     = "CAP"
+	Authenticate   = "AUTHENTICATE"
 	PrivateMessage = "PRIVMSG"
-
-	// Errors
-
-	// ERR_PASSWDMISMATCH
-	// TODO: Mappings from IRC error codes to go errors
-	ErrPasswordMismatch = "464"
+	// Join & Part are echoed back for our own nick, and for other users with the membership capability
+	Join = "JOIN"
+	Part = "PART"
 )
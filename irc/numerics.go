@@ -0,0 +1,389 @@
+package irc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+)
+
+// https://tools.ietf.org/html/rfc1459.html#section-6
+// https://tools.ietf.org/html/rfc2812.html#section-5
+// https://defs.ircdocs.horse/defs/numerics.html
+
+// Numeric is a three digit reply code sent by the server
+// Error numerics are errors themselves, so can be compared against a NumericError with errors.Is
+type Numeric string
+
+// NumericError is an error numeric received from the server
+type NumericError struct {
+	Numeric Numeric
+	Params  []string
+}
+
+// Replies
+const (
+	RplWelcome         Numeric = "001"
+	RplYourHost        Numeric = "002"
+	RplCreated         Numeric = "003"
+	RplMyInfo          Numeric = "004"
+	RplISupport        Numeric = "005"
+	RplBounce          Numeric = "010"
+	RplUModeIs         Numeric = "221"
+	RplLUserClient     Numeric = "251"
+	RplLUserOp         Numeric = "252"
+	RplLUserUnknown    Numeric = "253"
+	RplLUserChannels   Numeric = "254"
+	RplLUserMe         Numeric = "255"
+	RplAdminMe         Numeric = "256"
+	RplAdminLoc1       Numeric = "257"
+	RplAdminLoc2       Numeric = "258"
+	RplAdminEmail      Numeric = "259"
+	RplTryAgain        Numeric = "263"
+	RplLocalUsers      Numeric = "265"
+	RplGlobalUsers     Numeric = "266"
+	RplWhoisCertFP     Numeric = "276"
+	RplNone            Numeric = "300"
+	RplAway            Numeric = "301"
+	RplUserHost        Numeric = "302"
+	RplIsOn            Numeric = "303"
+	RplUnAway          Numeric = "305"
+	RplNowAway         Numeric = "306"
+	RplWhoisRegNick    Numeric = "307"
+	RplWhoisUser       Numeric = "311"
+	RplWhoisServer     Numeric = "312"
+	RplWhoisOperator   Numeric = "313"
+	RplWhoWasUser      Numeric = "314"
+	RplEndOfWho        Numeric = "315"
+	RplWhoisIdle       Numeric = "317"
+	RplEndOfWhois      Numeric = "318"
+	RplWhoisChannels   Numeric = "319"
+	RplListStart       Numeric = "321"
+	RplList            Numeric = "322"
+	RplListEnd         Numeric = "323"
+	RplChannelModeIs   Numeric = "324"
+	RplCreationTime    Numeric = "329"
+	RplWhoisAccount    Numeric = "330"
+	RplNoTopic         Numeric = "331"
+	RplTopic           Numeric = "332"
+	RplTopicWhoTime    Numeric = "333"
+	RplWhoisActually   Numeric = "338"
+	RplInviting        Numeric = "341"
+	RplInviteList      Numeric = "346"
+	RplEndOfInviteList Numeric = "347"
+	RplExceptList      Numeric = "348"
+	RplEndOfExceptList Numeric = "349"
+	RplVersion         Numeric = "351"
+	RplWhoReply        Numeric = "352"
+	RplNamReply        Numeric = "353"
+	RplLinks           Numeric = "364"
+	RplEndOfLinks      Numeric = "365"
+	RplEndOfNames      Numeric = "366"
+	RplBanList         Numeric = "367"
+	RplEndOfBanList    Numeric = "368"
+	RplEndOfWhoWas     Numeric = "369"
+	RplInfo            Numeric = "371"
+	RplMOTD            Numeric = "372"
+	RplEndOfInfo       Numeric = "374"
+	RplMOTDStart       Numeric = "375"
+	RplEndOfMOTD       Numeric = "376"
+	RplWhoisHost       Numeric = "378"
+	RplWhoisModes      Numeric = "379"
+	RplYoureOper       Numeric = "381"
+	RplRehashing       Numeric = "382"
+	RplTime            Numeric = "391"
+	RplHostHidden      Numeric = "396"
+	RplStartTLS        Numeric = "670"
+	RplWhoisSecure     Numeric = "671"
+	RplHelpStart       Numeric = "704"
+	RplHelpTxt         Numeric = "705"
+	RplEndOfHelp       Numeric = "706"
+	RplMonOnline       Numeric = "730"
+	RplMonOffline      Numeric = "731"
+	RplMonList         Numeric = "732"
+	RplEndOfMonList    Numeric = "733"
+	RplLoggedIn        Numeric = "900"
+	RplLoggedOut       Numeric = "901"
+	RplSASLSuccess     Numeric = "903"
+	RplSASLMechs       Numeric = "908"
+	RplWhoisKeyValue   Numeric = "760"
+	RplKeyValue        Numeric = "761"
+	RplMetadataEnd     Numeric = "762"
+	RplWhoisSpecial    Numeric = "320"
+	RplWhoisBot        Numeric = "335"
+)
+
+// Errors
+const (
+	ErrUnknownError      Numeric = "400"
+	ErrNoSuchNick        Numeric = "401"
+	ErrNoSuchServer      Numeric = "402"
+	ErrNoSuchChannel     Numeric = "403"
+	ErrCannotSendToChan  Numeric = "404"
+	ErrTooManyChannels   Numeric = "405"
+	ErrWasNoSuchNick     Numeric = "406"
+	ErrTooManyTargets    Numeric = "407"
+	ErrNoOrigin          Numeric = "409"
+	ErrInvalidCapCmd     Numeric = "410"
+	ErrNoRecipient       Numeric = "411"
+	ErrNoTextToSend      Numeric = "412"
+	ErrInputTooLong      Numeric = "417"
+	ErrUnknownCommand    Numeric = "421"
+	ErrNoMOTD            Numeric = "422"
+	ErrNoNicknameGiven   Numeric = "431"
+	ErrErroneousNickname Numeric = "432"
+	ErrNicknameInUse     Numeric = "433"
+	ErrNickCollision     Numeric = "436"
+	ErrUnavailResource   Numeric = "437"
+	ErrUserNotInChannel  Numeric = "441"
+	ErrNotOnChannel      Numeric = "442"
+	ErrUserOnChannel     Numeric = "443"
+	ErrNotRegistered     Numeric = "451"
+	ErrNeedMoreParams    Numeric = "461"
+	ErrAlreadyRegistered Numeric = "462"
+	ErrNoPermForHost     Numeric = "463"
+	ErrPasswordMismatch  Numeric = "464"
+	ErrYoureBannedCreep  Numeric = "465"
+	ErrKeySet            Numeric = "467"
+	ErrChannelIsFull     Numeric = "471"
+	ErrUnknownMode       Numeric = "472"
+	ErrInviteOnlyChan    Numeric = "473"
+	ErrBannedFromChan    Numeric = "474"
+	ErrBadChannelKey     Numeric = "475"
+	ErrBadChanMask       Numeric = "476"
+	ErrNoChanModes       Numeric = "477"
+	ErrBanListFull       Numeric = "478"
+	ErrNoPrivileges      Numeric = "481"
+	ErrChanOPrivsNeeded  Numeric = "482"
+	ErrCantKillServer    Numeric = "483"
+	ErrRestricted        Numeric = "484"
+	ErrNoOperHost        Numeric = "491"
+	ErrUModeUnknownFlag  Numeric = "501"
+	ErrUsersDontMatch    Numeric = "502"
+	ErrHelpNotFound      Numeric = "524"
+	ErrInvalidKey        Numeric = "525"
+	ErrStartTLS          Numeric = "691"
+	ErrInvalidModeParam  Numeric = "696"
+	ErrNoPrivs           Numeric = "723"
+	ErrMonListFull       Numeric = "734"
+	ErrMetadataLimit     Numeric = "764"
+	ErrTargetInvalid     Numeric = "765"
+	ErrNoMatchingKey     Numeric = "766"
+	ErrKeyInvalid        Numeric = "767"
+	ErrKeyNotSet         Numeric = "768"
+	ErrKeyNoPermission   Numeric = "769"
+	ErrNickLocked        Numeric = "902"
+	ErrSASLFail          Numeric = "904"
+	ErrSASLTooLong       Numeric = "905"
+	ErrSASLAborted       Numeric = "906"
+	ErrSASLAlready       Numeric = "907"
+)
+
+// numericNames are the names given to each numeric by the RFCs & IRCv3 specs
+var numericNames = map[Numeric]string{
+	RplWelcome:         "RPL_WELCOME",
+	RplYourHost:        "RPL_YOURHOST",
+	RplCreated:         "RPL_CREATED",
+	RplMyInfo:          "RPL_MYINFO",
+	RplISupport:        "RPL_ISUPPORT",
+	RplBounce:          "RPL_BOUNCE",
+	RplUModeIs:         "RPL_UMODEIS",
+	RplLUserClient:     "RPL_LUSERCLIENT",
+	RplLUserOp:         "RPL_LUSEROP",
+	RplLUserUnknown:    "RPL_LUSERUNKNOWN",
+	RplLUserChannels:   "RPL_LUSERCHANNELS",
+	RplLUserMe:         "RPL_LUSERME",
+	RplAdminMe:         "RPL_ADMINME",
+	RplAdminLoc1:       "RPL_ADMINLOC1",
+	RplAdminLoc2:       "RPL_ADMINLOC2",
+	RplAdminEmail:      "RPL_ADMINEMAIL",
+	RplTryAgain:        "RPL_TRYAGAIN",
+	RplLocalUsers:      "RPL_LOCALUSERS",
+	RplGlobalUsers:     "RPL_GLOBALUSERS",
+	RplWhoisCertFP:     "RPL_WHOISCERTFP",
+	RplNone:            "RPL_NONE",
+	RplAway:            "RPL_AWAY",
+	RplUserHost:        "RPL_USERHOST",
+	RplIsOn:            "RPL_ISON",
+	RplUnAway:          "RPL_UNAWAY",
+	RplNowAway:         "RPL_NOWAWAY",
+	RplWhoisRegNick:    "RPL_WHOISREGNICK",
+	RplWhoisUser:       "RPL_WHOISUSER",
+	RplWhoisServer:     "RPL_WHOISSERVER",
+	RplWhoisOperator:   "RPL_WHOISOPERATOR",
+	RplWhoWasUser:      "RPL_WHOWASUSER",
+	RplEndOfWho:        "RPL_ENDOFWHO",
+	RplWhoisIdle:       "RPL_WHOISIDLE",
+	RplEndOfWhois:      "RPL_ENDOFWHOIS",
+	RplWhoisChannels:   "RPL_WHOISCHANNELS",
+	RplWhoisSpecial:    "RPL_WHOISSPECIAL",
+	RplListStart:       "RPL_LISTSTART",
+	RplList:            "RPL_LIST",
+	RplListEnd:         "RPL_LISTEND",
+	RplChannelModeIs:   "RPL_CHANNELMODEIS",
+	RplCreationTime:    "RPL_CREATIONTIME",
+	RplWhoisAccount:    "RPL_WHOISACCOUNT",
+	RplNoTopic:         "RPL_NOTOPIC",
+	RplTopic:           "RPL_TOPIC",
+	RplTopicWhoTime:    "RPL_TOPICWHOTIME",
+	RplWhoisBot:        "RPL_WHOISBOT",
+	RplWhoisActually:   "RPL_WHOISACTUALLY",
+	RplInviting:        "RPL_INVITING",
+	RplInviteList:      "RPL_INVITELIST",
+	RplEndOfInviteList: "RPL_ENDOFINVITELIST",
+	RplExceptList:      "RPL_EXCEPTLIST",
+	RplEndOfExceptList: "RPL_ENDOFEXCEPTLIST",
+	RplVersion:         "RPL_VERSION",
+	RplWhoReply:        "RPL_WHOREPLY",
+	RplNamReply:        "RPL_NAMREPLY",
+	RplLinks:           "RPL_LINKS",
+	RplEndOfLinks:      "RPL_ENDOFLINKS",
+	RplEndOfNames:      "RPL_ENDOFNAMES",
+	RplBanList:         "RPL_BANLIST",
+	RplEndOfBanList:    "RPL_ENDOFBANLIST",
+	RplEndOfWhoWas:     "RPL_ENDOFWHOWAS",
+	RplInfo:            "RPL_INFO",
+	RplMOTD:            "RPL_MOTD",
+	RplEndOfInfo:       "RPL_ENDOFINFO",
+	RplMOTDStart:       "RPL_MOTDSTART",
+	RplEndOfMOTD:       "RPL_ENDOFMOTD",
+	RplWhoisHost:       "RPL_WHOISHOST",
+	RplWhoisModes:      "RPL_WHOISMODES",
+	RplYoureOper:       "RPL_YOUREOPER",
+	RplRehashing:       "RPL_REHASHING",
+	RplTime:            "RPL_TIME",
+	RplHostHidden:      "RPL_HOSTHIDDEN",
+	RplStartTLS:        "RPL_STARTTLS",
+	RplWhoisSecure:     "RPL_WHOISSECURE",
+	RplHelpStart:       "RPL_HELPSTART",
+	RplHelpTxt:         "RPL_HELPTXT",
+	RplEndOfHelp:       "RPL_ENDOFHELP",
+	RplMonOnline:       "RPL_MONONLINE",
+	RplMonOffline:      "RPL_MONOFFLINE",
+	RplMonList:         "RPL_MONLIST",
+	RplEndOfMonList:    "RPL_ENDOFMONLIST",
+	RplWhoisKeyValue:   "RPL_WHOISKEYVALUE",
+	RplKeyValue:        "RPL_KEYVALUE",
+	RplMetadataEnd:     "RPL_METADATAEND",
+	RplLoggedIn:        "RPL_LOGGEDIN",
+	RplLoggedOut:       "RPL_LOGGEDOUT",
+	RplSASLSuccess:     "RPL_SASLSUCCESS",
+	RplSASLMechs:       "RPL_SASLMECHS",
+
+	ErrUnknownError:      "ERR_UNKNOWNERROR",
+	ErrNoSuchNick:        "ERR_NOSUCHNICK",
+	ErrNoSuchServer:      "ERR_NOSUCHSERVER",
+	ErrNoSuchChannel:     "ERR_NOSUCHCHANNEL",
+	ErrCannotSendToChan:  "ERR_CANNOTSENDTOCHAN",
+	ErrTooManyChannels:   "ERR_TOOMANYCHANNELS",
+	ErrWasNoSuchNick:     "ERR_WASNOSUCHNICK",
+	ErrTooManyTargets:    "ERR_TOOMANYTARGETS",
+	ErrNoOrigin:          "ERR_NOORIGIN",
+	ErrInvalidCapCmd:     "ERR_INVALIDCAPCMD",
+	ErrNoRecipient:       "ERR_NORECIPIENT",
+	ErrNoTextToSend:      "ERR_NOTEXTTOSEND",
+	ErrInputTooLong:      "ERR_INPUTTOOLONG",
+	ErrUnknownCommand:    "ERR_UNKNOWNCOMMAND",
+	ErrNoMOTD:            "ERR_NOMOTD",
+	ErrNoNicknameGiven:   "ERR_NONICKNAMEGIVEN",
+	ErrErroneousNickname: "ERR_ERRONEUSNICKNAME",
+	ErrNicknameInUse:     "ERR_NICKNAMEINUSE",
+	ErrNickCollision:     "ERR_NICKCOLLISION",
+	ErrUnavailResource:   "ERR_UNAVAILRESOURCE",
+	ErrUserNotInChannel:  "ERR_USERNOTINCHANNEL",
+	ErrNotOnChannel:      "ERR_NOTONCHANNEL",
+	ErrUserOnChannel:     "ERR_USERONCHANNEL",
+	ErrNotRegistered:     "ERR_NOTREGISTERED",
+	ErrNeedMoreParams:    "ERR_NEEDMOREPARAMS",
+	ErrAlreadyRegistered: "ERR_ALREADYREGISTERED",
+	ErrNoPermForHost:     "ERR_NOPERMFORHOST",
+	ErrPasswordMismatch:  "ERR_PASSWDMISMATCH",
+	ErrYoureBannedCreep:  "ERR_YOUREBANNEDCREEP",
+	ErrKeySet:            "ERR_KEYSET",
+	ErrChannelIsFull:     "ERR_CHANNELISFULL",
+	ErrUnknownMode:       "ERR_UNKNOWNMODE",
+	ErrInviteOnlyChan:    "ERR_INVITEONLYCHAN",
+	ErrBannedFromChan:    "ERR_BANNEDFROMCHAN",
+	ErrBadChannelKey:     "ERR_BADCHANNELKEY",
+	ErrBadChanMask:       "ERR_BADCHANMASK",
+	ErrNoChanModes:       "ERR_NOCHANMODES",
+	ErrBanListFull:       "ERR_BANLISTFULL",
+	ErrNoPrivileges:      "ERR_NOPRIVILEGES",
+	ErrChanOPrivsNeeded:  "ERR_CHANOPRIVSNEEDED",
+	ErrCantKillServer:    "ERR_CANTKILLSERVER",
+	ErrRestricted:        "ERR_RESTRICTED",
+	ErrNoOperHost:        "ERR_NOOPERHOST",
+	ErrUModeUnknownFlag:  "ERR_UMODEUNKNOWNFLAG",
+	ErrUsersDontMatch:    "ERR_USERSDONTMATCH",
+	ErrHelpNotFound:      "ERR_HELPNOTFOUND",
+	ErrInvalidKey:        "ERR_INVALIDKEY",
+	ErrStartTLS:          "ERR_STARTTLS",
+	ErrInvalidModeParam:  "ERR_INVALIDMODEPARAM",
+	ErrNoPrivs:           "ERR_NOPRIVS",
+	ErrMonListFull:       "ERR_MONLISTFULL",
+	ErrMetadataLimit:     "ERR_METADATALIMIT",
+	ErrTargetInvalid:     "ERR_TARGETINVALID",
+	ErrNoMatchingKey:     "ERR_NOMATCHINGKEY",
+	ErrKeyInvalid:        "ERR_KEYINVALID",
+	ErrKeyNotSet:         "ERR_KEYNOTSET",
+	ErrKeyNoPermission:   "ERR_KEYNOPERMISSION",
+	ErrNickLocked:        "ERR_NICKLOCKED",
+	ErrSASLFail:          "ERR_SASLFAIL",
+	ErrSASLTooLong:       "ERR_SASLTOOLONG",
+	ErrSASLAborted:       "ERR_SASLABORTED",
+	ErrSASLAlready:       "ERR_SASLALREADY",
+}
+
+// IsNumeric returns whether the command is a three digit numeric
+func IsNumeric(command string) bool {
+	if len(command) != 3 {
+		return false
+	}
+	for _, r := range command {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Name returns the name of the numeric, or the numeric itself if it's unknown
+func (n Numeric) Name() string {
+	if name, ok := numericNames[n]; ok {
+		return name
+	}
+	return string(n)
+}
+
+// IsError returns whether the numeric is an error, unknown numerics are errors in the 400-599 range
+func (n Numeric) IsError() bool {
+	if name, ok := numericNames[n]; ok {
+		return strings.HasPrefix(name, "ERR_")
+	}
+	return IsNumeric(string(n)) && n >= "400" && n < "600"
+}
+
+func (n Numeric) Error() string {
+	return fmt.Sprintf("%v (%v)", n.Name(), string(n))
+}
+
+// NumericErr returns a *NumericError if the message is an error numeric, otherwise nil
+func NumericErr(message parser.Message) error {
+	n := Numeric(message.Command)
+	if !n.IsError() {
+		return nil
+	}
+	return &NumericError{Numeric: n, Params: message.Params}
+}
+
+func (e *NumericError) Error() string {
+	// The last param is the human readable description
+	if len(e.Params) == 0 {
+		return e.Numeric.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Numeric.Error(), e.Params[len(e.Params)-1])
+}
+
+func (e *NumericError) Unwrap() error {
+	return e.Numeric
+}
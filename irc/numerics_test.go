@@ -0,0 +1,49 @@
+package irc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumeric(t *testing.T) {
+	assert.Equal(t, "RPL_WELCOME", RplWelcome.Name())
+	assert.Equal(t, "ERR_NICKNAMEINUSE", ErrNicknameInUse.Name())
+	assert.Equal(t, "499", Numeric("499").Name())
+
+	assert.False(t, RplEndOfMOTD.IsError())
+	assert.True(t, ErrBannedFromChan.IsError())
+	assert.True(t, ErrSASLFail.IsError())
+	// Unknown numerics are errors in the 400-599 range
+	assert.True(t, Numeric("499").IsError())
+	assert.False(t, Numeric("999").IsError())
+	assert.False(t, Numeric("PRIVMSG").IsError())
+}
+
+func TestIsNumeric(t *testing.T) {
+	assert.True(t, IsNumeric("001"))
+	assert.False(t, IsNumeric("01"))
+	assert.False(t, IsNumeric("0O1"))
+	assert.False(t, IsNumeric(PrivateMessage))
+}
+
+func TestNumericErr(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		err := NumericErr(parser.Message{Prefix: "irc.example.com", Command: "433", Params: []string{"*", "bot", "Nickname is already in use"}})
+		assert.ErrorIs(t, err, ErrNicknameInUse)
+		assert.False(t, errors.Is(err, ErrErroneousNickname))
+		assert.EqualError(t, err, "ERR_NICKNAMEINUSE (433): Nickname is already in use")
+
+		var numericErr *NumericError
+		if assert.ErrorAs(t, err, &numericErr) {
+			assert.Equal(t, "bot", numericErr.Params[1])
+		}
+	})
+
+	t.Run("Reply", func(t *testing.T) {
+		assert.NoError(t, NumericErr(parser.Message{Command: string(RplWelcome), Params: []string{"bot", "Welcome"}}))
+		assert.NoError(t, NumericErr(parser.Message{Command: PrivateMessage, Params: []string{"#channel", "hi"}}))
+	})
+}
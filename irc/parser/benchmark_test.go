@@ -2,11 +2,17 @@ package parser
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 )
 
 var res *Message
 
+const (
+	longLine  = "@badge-info=subscriber/8;badges=subscriber/6,bits/75000;color=#1E90FF;display-name=Ovojaytee;emotes=1837404:44-50/915234:164-169/1093027:13-18;flags=;id=aa52e1d2-6ff5-42ba-b205-9d4a15f9dbf8;login=ovojaytee;mod=0;msg-id=resub;msg-param-cumulative-months=7;msg-param-months=0;msg-param-should-share-streak=1;msg-param-streak-months=8;msg-param-sub-plan-name=Channel\\sSubscription\\s(loeya);msg-param-sub-plan=1000;room-id=166279350;subscriber=1;system-msg=Ovojaytee\\ssubscribed\\sat\\sTier\\s1.\\sThey've\\ssubscribed\\sfor\\s8\\smonths,\\scurrently\\son\\sa\\s8\\smonth\\sstreak!;tmi-sent-ts=1558352544376;user-id=160605648;user-type= :tmi.twitch.tv USERNOTICE #loeya :Wow 8 months loeyaH our baby is almost here loeyaHM can we name him Zlatan ? Thanks Queen for always starting off my day on a good note with your wonderful content loeya1"
+	shortLine = ":tmi.twitch.tv CAP * ACK :twitch.tv/tags twitch.tv/commands twitch.tv/membership"
+)
+
 func BenchmarkScanner_Scan(b *testing.B) {
 	b.StopTimer()
 	var r *Message
@@ -40,3 +46,37 @@ func BenchmarkScanner_Scan(b *testing.B) {
 		res = r
 	})
 }
+
+func BenchmarkScanner_ScanInto(b *testing.B) {
+	for name, line := range map[string]string{"Long": longLine, "Short": shortLine} {
+		b.Run(name, func(b *testing.B) {
+			// Enough lines to fill b.N without writing to the reader inside the timer
+			reader := strings.NewReader(strings.Repeat(line+"\r\n", b.N))
+			scanner := NewScanner(reader)
+			var message Message
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				if err := scanner.ScanInto(&message); err != nil {
+					b.Fatalf("failed to scan: %v", err)
+				}
+			}
+			res = &message
+		})
+	}
+}
+
+func BenchmarkParseLine(b *testing.B) {
+	for name, line := range map[string][]byte{"Long": []byte(longLine), "Short": []byte(shortLine)} {
+		b.Run(name, func(b *testing.B) {
+			var message Message
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				if err := ParseLine(line, &message); err != nil {
+					b.Fatalf("failed to parse: %v", err)
+				}
+			}
+			res = &message
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// atoms are the parts of a message in the ircv3 parser-tests
+	atoms struct {
+		Tags   map[string]string `yaml:"tags"`
+		Source string            `yaml:"source"`
+		Verb   string            `yaml:"verb"`
+		Params []string          `yaml:"params"`
+	}
+
+	splitTest struct {
+		Desc  string `yaml:"desc"`
+		Input string `yaml:"input"`
+		Atoms atoms  `yaml:"atoms"`
+	}
+
+	joinTest struct {
+		Desc    string   `yaml:"desc"`
+		Atoms   atoms    `yaml:"atoms"`
+		Matches []string `yaml:"matches"`
+	}
+)
+
+func (a atoms) message() Message {
+	m := Message{
+		Tags:    Tags(a.Tags),
+		Prefix:  Prefix(a.Source),
+		Command: a.Verb,
+		Params:  Params(a.Params),
+	}
+	if m.Tags == nil {
+		m.Tags = Tags{}
+	}
+	if m.Params == nil {
+		m.Params = Params{}
+	}
+	return m
+}
+
+func loadVectors(t *testing.T, file string, v interface{}) {
+	t.Helper()
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, yaml.NewDecoder(f).Decode(v))
+}
+
+func TestConformance_Split(t *testing.T) {
+	var vectors struct {
+		Tests []splitTest `yaml:"tests"`
+	}
+	loadVectors(t, "testdata/msg-split.yaml", &vectors)
+	require.NotEmpty(t, vectors.Tests)
+	for _, test := range vectors.Tests {
+		name := test.Desc
+		if len(name) == 0 {
+			name = test.Input
+		}
+		t.Run(name, func(t *testing.T) {
+			var message Message
+			require.NoError(t, ParseLine([]byte(test.Input), &message))
+			assert.Equal(t, test.Atoms.message(), message)
+		})
+	}
+}
+
+func TestConformance_Join(t *testing.T) {
+	var vectors struct {
+		Tests []joinTest `yaml:"tests"`
+	}
+	loadVectors(t, "testdata/msg-join.yaml", &vectors)
+	require.NotEmpty(t, vectors.Tests)
+	for _, test := range vectors.Tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			bs, err := test.Atoms.message().MarshalText()
+			require.NoError(t, err)
+			assert.Contains(t, test.Matches, string(bs))
+		})
+	}
+}
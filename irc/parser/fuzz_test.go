@@ -0,0 +1,64 @@
+//go:build go1.18
+// +build go1.18
+
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzScanner_Scan(f *testing.F) {
+	f.Add([]byte(shortLine + "\r\n"))
+	f.Add([]byte(longLine + "\r\n"))
+	f.Add([]byte("PING :tmi.twitch.tv\r\n:nick!user@host JOIN #channel\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		scanner := NewScanner(bytes.NewReader(data))
+		// Every call reads at least one byte until EOF, so this bounds the loop if the scanner stops advancing
+		for i := 0; i <= len(data); i++ {
+			message, err := scanner.Scan()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				continue
+			}
+			line, err := message.MarshalText()
+			if err != nil {
+				continue
+			}
+			// Anything which can be serialized should parse back into the same message
+			var parsed Message
+			require.NoError(t, ParseLine(line, &parsed), "%q", line)
+			assert.Equal(t, message.Tags, parsed.Tags, "%q", line)
+			assert.Equal(t, message.Prefix, parsed.Prefix, "%q", line)
+			assert.Equal(t, message.Command, parsed.Command, "%q", line)
+			assert.Equal(t, message.Params, parsed.Params, "%q", line)
+		}
+		t.Fatalf("scanner didn't reach EOF")
+	})
+}
+
+func FuzzMessage_MarshalText(f *testing.F) {
+	f.Add("display-name", "Some User")
+	f.Add("+example.com/key", "a=b;c\\d")
+	f.Add("x :evil!e@e QUIT", "y")
+	f.Fuzz(func(t *testing.T, key, value string) {
+		message := Message{Tags: Tags{key: value}, Command: "PRIVMSG", Params: Params{"#channel", "hi"}}
+		line, err := message.MarshalText()
+		if err != nil {
+			return
+		}
+		// A tag which can be serialized must not change the rest of the line
+		var parsed Message
+		require.NoError(t, ParseLine(line, &parsed), "%q", line)
+		assert.Equal(t, message.Tags, parsed.Tags, "%q", line)
+		assert.Equal(t, message.Command, parsed.Command, "%q", line)
+		assert.Equal(t, message.Params, parsed.Params, "%q", line)
+	})
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"strings"
+)
+
+const (
+	// maxTagLength is the most bytes of tags a server can send, including the @ & trailing space
+	maxTagLength = 8191
+	// maxBodyLength is the most bytes the rest of the line can be, including the CRLF
+	// RFC 1459 limits this to 512, but Twitch allows 500 characters of chat which can be several times that in UTF-8
+	maxBodyLength = 4096
+	// maxLineLength is the most bytes a line can be, including tags & the CRLF
+	maxLineLength = maxTagLength + maxBodyLength
+)
+
+// escapedMap maps the character after a \ in a tag value to what it represents
+var escapedMap = map[byte]byte{
+	':':  ';',
+	's':  ' ',
+	'\\': '\\',
+	'r':  '\r',
+	'n':  '\n',
+}
+
+// ParseLine parses a single line without the CRLF into the message, reusing its tags & params
+// The line is converted into a string once & every token is a substring of it, so a reused message
+// only allocates for the line itself & any tag values which need unescaping, it isn't allocation free
+// as the tokens have to outlive the buffer the line was read into
+func ParseLine(line []byte, message *Message) error {
+	if message.Tags == nil {
+		message.Tags = make(Tags)
+	}
+	for k := range message.Tags {
+		delete(message.Tags, k)
+	}
+	if message.Params == nil {
+		message.Params = make(Params, 0)
+	}
+	message.Params = message.Params[:0]
+	message.Prefix = ""
+	message.Command = ""
+
+	if len(line) == 0 {
+		return ErrEmptyMessage
+	}
+	str := string(line)
+	i := 0
+	if str[0] == '@' {
+		end := strings.IndexByte(str, ' ')
+		if end < 0 {
+			return ErrNoCommand
+		}
+		if end+1 > maxTagLength {
+			return ErrTagsTooLong
+		}
+		parseTags(str[1:end], message.Tags)
+		i = skipSpaces(str, end)
+	}
+	if len(str)-i+2 > maxBodyLength {
+		return ErrTooLong
+	}
+
+	if i < len(str) && str[i] == ':' {
+		end := strings.IndexByte(str[i:], ' ')
+		if end < 0 {
+			return ErrNoCommand
+		}
+		if end == 1 {
+			return ErrNoPrefix
+		}
+		message.Prefix = Prefix(str[i+1 : i+end])
+		i = skipSpaces(str, i+end)
+	}
+
+	end := strings.IndexByte(str[i:], ' ')
+	if end < 0 {
+		end = len(str) - i
+	}
+	if end == 0 {
+		return ErrNoCommand
+	}
+	message.Command = str[i : i+end]
+	i += end
+
+	for {
+		i = skipSpaces(str, i)
+		if i >= len(str) {
+			return nil
+		}
+		if str[i] == ':' {
+			message.Params = append(message.Params, str[i+1:])
+			return nil
+		}
+		end := strings.IndexByte(str[i:], ' ')
+		if end < 0 {
+			end = len(str) - i
+		}
+		message.Params = append(message.Params, str[i:i+end])
+		i += end
+	}
+}
+
+// parseTags parses the tags without the @ into the map
+// Tags without a value are kept with an empty value, & a repeated key overwrites the earlier value
+func parseTags(str string, tags Tags) {
+	for len(str) > 0 {
+		var tag string
+		if end := strings.IndexByte(str, ';'); end >= 0 {
+			tag, str = str[:end], str[end+1:]
+		} else {
+			tag, str = str, ""
+		}
+		key, value := tag, ""
+		if eq := strings.IndexByte(tag, '='); eq >= 0 {
+			key, value = tag[:eq], unescapeTag(tag[eq+1:])
+		}
+		if len(key) > 0 {
+			tags[key] = value
+		}
+	}
+}
+
+// unescapeTag unescapes a tag value, only allocating if it contains an escape
+func unescapeTag(value string) string {
+	if strings.IndexByte(value, '\\') < 0 {
+		return value
+	}
+	var sb strings.Builder
+	sb.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		// A trailing \ is dropped
+		if i == len(value) {
+			break
+		}
+		if escaped, ok := escapedMap[value[i]]; ok {
+			sb.WriteByte(escaped)
+		} else {
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String()
+}
+
+func skipSpaces(str string, i int) int {
+	for i < len(str) && str[i] == ' ' {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	t.Run("Reused message", func(t *testing.T) {
+		var message Message
+		require.NoError(t, ParseLine([]byte("@id=1;color=red :user!user@host PRIVMSG #channel :hello there"), &message))
+		assert.Equal(t, Message{
+			Tags:    Tags{"id": "1", "color": "red"},
+			Prefix:  "user!user@host",
+			Command: "PRIVMSG",
+			Params:  Params{"#channel", "hello there"},
+		}, message)
+
+		// Nothing from the previous line is left behind
+		require.NoError(t, ParseLine([]byte("PING tmi.twitch.tv"), &message))
+		assert.Equal(t, Message{
+			Tags:    Tags{},
+			Command: "PING",
+			Params:  Params{"tmi.twitch.tv"},
+		}, message)
+	})
+
+	t.Run("Extra spaces", func(t *testing.T) {
+		var message Message
+		require.NoError(t, ParseLine([]byte(":prefix  JOIN   #channel  "), &message))
+		assert.Equal(t, "JOIN", message.Command)
+		assert.Equal(t, Params{"#channel"}, message.Params)
+	})
+
+	t.Run("Escaped tags", func(t *testing.T) {
+		var message Message
+		require.NoError(t, ParseLine([]byte(`@a=semi\:space\sslash\\cr\rlf\n;b=unknown\x;c=trailing\ CMD`), &message))
+		assert.Equal(t, Tags{"a": "semi;space slash\\cr\rlf\n", "b": "unknownx", "c": "trailing"}, message.Tags)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		var message Message
+		assert.ErrorIs(t, ParseLine([]byte(""), &message), ErrEmptyMessage)
+		assert.ErrorIs(t, ParseLine([]byte("@a=b"), &message), ErrNoCommand)
+		assert.ErrorIs(t, ParseLine([]byte(": CMD"), &message), ErrNoPrefix)
+		assert.ErrorIs(t, ParseLine([]byte(":prefix"), &message), ErrNoCommand)
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		var message Message
+		// The tags & the rest of the line have separate limits
+		tags := "@a=" + strings.Repeat("b", maxTagLength-5) + " "
+		require.NoError(t, ParseLine([]byte(tags+"PRIVMSG #channel :hi"), &message))
+		assert.Len(t, message.Tags["a"], maxTagLength-5)
+
+		tags = "@a=" + strings.Repeat("b", maxTagLength) + " "
+		assert.ErrorIs(t, ParseLine([]byte(tags+"PRIVMSG #channel :hi"), &message), ErrTagsTooLong)
+		assert.ErrorIs(t, ParseLine([]byte("PRIVMSG #channel :"+strings.Repeat("a", maxBodyLength)), &message), ErrTooLong)
+	})
+}
+
+func TestScanner_ScanInto(t *testing.T) {
+	long := "PRIVMSG #channel :" + strings.Repeat("a", maxLineLength)
+	scanner := NewScanner(strings.NewReader(long + "\r\nJOIN #channel\nPART #channel\r\n"))
+	var message Message
+
+	// The over long line is skipped, so the next line can still be read
+	assert.ErrorIs(t, scanner.ScanInto(&message), ErrTooLong)
+	require.NoError(t, scanner.ScanInto(&message))
+	assert.Equal(t, "JOIN", message.Command)
+	require.NoError(t, scanner.ScanInto(&message))
+	assert.Equal(t, "PART", message.Command)
+}
+
+func TestTags(t *testing.T) {
+	tags := Tags{"flags": "", "+draft/reply": "1"}
+	assert.True(t, tags.Has("flags"))
+	assert.False(t, tags.Has("emotes"))
+
+	assert.True(t, ClientOnlyTag("+draft/reply"))
+	assert.False(t, ClientOnlyTag("draft/reply"))
+	assert.Equal(t, "draft", TagVendor("+draft/reply"))
+	assert.Equal(t, "twitch.tv", TagVendor("twitch.tv/flag"))
+	assert.Equal(t, "", TagVendor("msg-id"))
+}
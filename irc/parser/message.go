@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidParam  = errors.New("invalid middle param")
+	ErrInvalidTagKey = errors.New("invalid tag key")
+)
+
+// tagEscaper escapes tag values, the reverse of escapedMap
+var tagEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\:`,
+	" ", `\s`,
+	"\r", `\r`,
+	"\n", `\n`,
+)
+
+// Bytes serializes the message without the CRLF, so any Message can be sent as a client.IrcMessage
+// Tags are sorted by key to keep the output stable, the tag keys & params aren't validated so client.Send uses MarshalText instead
+func (m Message) Bytes() []byte {
+	var sb strings.Builder
+	if len(m.Tags) > 0 {
+		keys := make([]string, 0, len(m.Tags))
+		for k := range m.Tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		sb.WriteByte('@')
+		for i, k := range keys {
+			if i > 0 {
+				sb.WriteByte(';')
+			}
+			sb.WriteString(k)
+			if v := m.Tags[k]; len(v) > 0 {
+				sb.WriteByte('=')
+				sb.WriteString(tagEscaper.Replace(v))
+			}
+		}
+		sb.WriteByte(' ')
+	}
+	if len(m.Prefix) > 0 {
+		sb.WriteByte(':')
+		sb.WriteString(string(m.Prefix))
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(m.Command)
+	for i, p := range m.Params {
+		sb.WriteByte(' ')
+		// The last param only needs to be trailing if it couldn't be read as a middle param
+		if i == len(m.Params)-1 && !isMiddle(p) {
+			sb.WriteByte(':')
+		}
+		sb.WriteString(p)
+	}
+	return []byte(sb.String())
+}
+
+// MarshalText serializes the message like Bytes, returning an error if it can't be read back by Scan
+func (m Message) MarshalText() ([]byte, error) {
+	if len(m.Command) == 0 {
+		return nil, ErrNoCommand
+	}
+	for k := range m.Tags {
+		if !isTagKey(k) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTagKey, k)
+		}
+	}
+	for i, p := range m.Params {
+		if i < len(m.Params)-1 && !isMiddle(p) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidParam, p)
+		}
+	}
+	return m.Bytes(), nil
+}
+
+// UnmarshalText parses a single message, the CRLF is optional
+func (m *Message) UnmarshalText(text []byte) error {
+	if !bytes.HasSuffix(text, []byte("\r\n")) {
+		text = append(text[:len(text):len(text)], '\r', '\n')
+	}
+	scanner := NewScanner(bytes.NewReader(text))
+	message, err := scanner.Scan()
+	if err != nil {
+		return err
+	}
+	*m = *message
+	return nil
+}
+
+// isMiddle returns whether the param can be sent without a leading :
+func isMiddle(param string) bool {
+	return len(param) > 0 && param[0] != ':' && !strings.Contains(param, " ")
+}
+
+// isTagKey returns whether the key can be sent without changing how the rest of the line is parsed, as keys aren't escaped
+func isTagKey(key string) bool {
+	return len(key) > 0 && !strings.ContainsAny(key, " ;=\r\n\x00")
+}
@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_Bytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  Message
+		expected string
+	}{
+		{
+			name:     "Command",
+			message:  Message{Command: "RECONNECT"},
+			expected: "RECONNECT",
+		},
+		{
+			name:     "Middle params",
+			message:  Message{Command: "JOIN", Params: Params{"#channel"}},
+			expected: "JOIN #channel",
+		},
+		{
+			name:     "Trailing",
+			message:  Message{Prefix: "bot!bot@bot.tmi.twitch.tv", Command: "PRIVMSG", Params: Params{"#channel", "hello world"}},
+			expected: ":bot!bot@bot.tmi.twitch.tv PRIVMSG #channel :hello world",
+		},
+		{
+			name:     "Trailing colon",
+			message:  Message{Command: "PRIVMSG", Params: Params{"#channel", ":)"}},
+			expected: "PRIVMSG #channel ::)",
+		},
+		{
+			name:     "Empty trailing",
+			message:  Message{Command: "TOPIC", Params: Params{"#channel", ""}},
+			expected: "TOPIC #channel :",
+		},
+		{
+			name: "Tags",
+			message: Message{
+				Tags:    Tags{"reply-parent-msg-id": "b34ccfc7-4977-403a-8a94-33c6bac34fb8", "client-nonce": "a b;c\\d\r\n", "flag": ""},
+				Command: "PRIVMSG",
+				Params:  Params{"#channel", "reply"},
+			},
+			expected: `@client-nonce=a\sb\:c\\d\r\n;flag;reply-parent-msg-id=b34ccfc7-4977-403a-8a94-33c6bac34fb8 PRIVMSG #channel reply`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, string(test.message.Bytes()))
+		})
+	}
+}
+
+func TestMessage_RoundTrip(t *testing.T) {
+	messages := []Message{
+		{
+			Tags:    Tags{"display-name": "Some User", "msg": "a=b;c\\d", "emotes": "25:0-4"},
+			Prefix:  "user!user@user.tmi.twitch.tv",
+			Command: "PRIVMSG",
+			Params:  Params{"#channel", "Kappa :) hello"},
+		},
+		{
+			Tags:    Tags{},
+			Prefix:  "tmi.twitch.tv",
+			Command: "CAP",
+			Params:  Params{"*", "ACK", "twitch.tv/tags"},
+		},
+		{
+			Tags:    Tags{},
+			Command: "PING",
+			Params:  Params{":tmi.twitch.tv"},
+		},
+	}
+	for _, message := range messages {
+		bs, err := message.MarshalText()
+		require.NoError(t, err)
+		scanner := NewScanner(strings.NewReader(string(bs) + "\r\n"))
+		scanned, err := scanner.Scan()
+		require.NoError(t, err)
+		assert.Equal(t, message, *scanned)
+
+		var unmarshalled Message
+		require.NoError(t, unmarshalled.UnmarshalText(bs))
+		assert.Equal(t, message, unmarshalled)
+	}
+}
+
+func TestMessage_MarshalText_Invalid(t *testing.T) {
+	_, err := Message{Params: Params{"#channel"}}.MarshalText()
+	assert.ErrorIs(t, err, ErrNoCommand)
+
+	_, err = Message{Command: "PRIVMSG", Params: Params{"#a channel", "hello"}}.MarshalText()
+	assert.ErrorIs(t, err, ErrInvalidParam)
+
+	_, err = Message{Command: "PRIVMSG", Params: Params{"", "hello"}}.MarshalText()
+	assert.ErrorIs(t, err, ErrInvalidParam)
+
+	// Keys aren't escaped, so these would change the rest of the line, such as the command
+	for _, key := range []string{"", "x :evil!e@e QUIT", "a;b", "a=b", "a\rb", "a\nb", "a\x00b"} {
+		_, err = Message{Tags: Tags{key: "y"}, Command: "PRIVMSG", Params: Params{"#c", "hi"}}.MarshalText()
+		assert.ErrorIs(t, err, ErrInvalidTagKey, "%q", key)
+	}
+}
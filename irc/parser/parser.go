@@ -8,16 +8,12 @@ import (
 	"strings"
 )
 
-// maxMessageLength is the maximum amount of runes to read before returning an error
-const maxMessageLength = 512
-
 var (
-	eof = rune(0)
-
 	ErrEmptyMessage = errors.New("empty message")
 	ErrNoCommand    = errors.New("no command")
 	ErrNoPrefix     = errors.New("no prefix")
 	ErrTooLong      = errors.New("read for too long")
+	ErrTagsTooLong  = errors.New("tags are too long")
 )
 
 // https://ircv3.net/specs/extensions/message-tags.html
@@ -49,15 +45,45 @@ func (t Tags) GetOrDefault(key, def string) (v string) {
 	return
 }
 
+// Has returns whether the tag was sent, including tags without a value
+func (t Tags) Has(key string) bool {
+	_, ok := t[key]
+	return ok
+}
+
+// ClientOnlyTag returns whether the key is a client-only tag, which the server passes on without processing
+func ClientOnlyTag(key string) bool {
+	return strings.HasPrefix(key, "+")
+}
+
+// TagVendor returns the vendor of the key, such as twitch.tv for +twitch.tv/foo, or "" for standardised tags
+func TagVendor(key string) string {
+	key = strings.TrimPrefix(key, "+")
+	if i := strings.LastIndexByte(key, '/'); i >= 0 {
+		return key[:i]
+	}
+	return ""
+}
+
 func (t Tags) User() string {
 	return t.GetOrDefault("display-name", "")
 }
 
+// User returns the nick, or the server name for messages from the server
 func (p Prefix) User() string {
-	return strings.SplitN(string(p), "!", 2)[0]
+	return p.Source().Name
 }
 
+// Source parses the prefix into its parts
+func (p Prefix) Source() Source {
+	return ParseSource(string(p))
+}
+
+// Channel returns the first param without the # prefix, or "" if it isn't a channel
 func (p Params) Channel() string {
+	if len(p) == 0 || len(p[0]) == 0 || p[0][0] != '#' {
+		return ""
+	}
 	return p[0][1:]
 }
 
@@ -71,275 +97,45 @@ func (m *Message) HasPrefix() bool {
 
 func NewScanner(r io.Reader) Scanner {
 	return Scanner{
-		Reader: bufio.NewReader(r),
+		Reader: bufio.NewReaderSize(r, maxLineLength),
 	}
 }
 
-// TODO: EOF checks
-// TODO: Escape inside of other funcs
-// TODO: Maximum length check, currently we do a check in readUntil, but there is a maximum of 512 chars in an entire message including CRLF
 // Scan scans a line from the reader
 func (s *Scanner) Scan() (*Message, error) {
-	if s.isCrlf() {
-		return nil, ErrEmptyMessage
-	}
-
-	r, err := s.peekRune()
-	if err != nil {
-		return nil, fmt.Errorf("failed to peek rune due to %w", err)
-	}
-
-	if r == eof {
-		return nil, io.EOF
-	}
-
-	message := &Message{
-		Tags:   make(map[string]string),
-		Params: make([]string, 0),
-	}
-
-	if r == '@' {
-		s.consume()
-		if message.Tags, err = s.readTags(); err != nil {
-			return nil, fmt.Errorf("failed to read tags due to %w", err)
-		}
-
-		if r, err = s.peekRune(); err != nil {
-			return nil, fmt.Errorf("failed to peek rune due to %w", err)
-		}
+	message := &Message{}
+	if err := s.ScanInto(message); err != nil {
+		return nil, err
 	}
-
-	if r == ':' {
-		s.consume()
-		if message.Prefix, err = s.readPrefix(); err != nil {
-			return nil, fmt.Errorf("failed to read prefix due to %w", err)
-		}
-	}
-
-	if message.Command, err = s.readCommand(); err != nil {
-		return nil, fmt.Errorf("failed to read command due to %w", err)
-	}
-
-	if message.Params, err = s.readParams(); err != nil {
-		return nil, fmt.Errorf("failed to read params due to %w", err)
-	}
-
 	return message, nil
 }
 
-var escapedMap = map[rune]rune{
-	':':  ';',
-	's':  ' ',
-	'\\': '\\',
-	'r':  '\r',
-	'n':  '\n',
-}
-
-// readTags Reads the tags BNF
-func (s *Scanner) readTags() (map[string]string, error) {
-	tags := make(map[string]string)
-	key := true
-	var keyBuilder strings.Builder
-	var valueBuilder strings.Builder
-
-	for {
-		escaped := false
-		r := s.read()
-
-		if r == '\\' {
-			escaped = true
-			r = s.read()
-		}
-
-		if !escaped {
-			if r == ' ' {
-				// Push key & value pair to map if not empty, return tags
-				if keyBuilder.Len() > 0 && valueBuilder.Len() > 0 {
-					tags[keyBuilder.String()] = valueBuilder.String()
-				}
-				return tags, nil
-			}
-
-			if r == '=' {
-				key = false
-				continue
-			}
-
-			if r == ';' {
-				// Push key & value pair to map if not empty
-				if keyBuilder.Len() > 0 && valueBuilder.Len() > 0 {
-					tags[keyBuilder.String()] = valueBuilder.String()
-				}
-
-				keyBuilder.Reset()
-				valueBuilder.Reset()
-				key = true
-				continue
-			}
-
-		}
-
-		if escaped {
-			if escapedRune, ok := escapedMap[r]; ok {
-				r = escapedRune
-			}
-		}
-
-		if key {
-			keyBuilder.WriteRune(r)
-		} else {
-			valueBuilder.WriteRune(r)
-		}
-	}
-}
-
-// readPrefix Reads the prefix BNF
-func (s *Scanner) readPrefix() (pre Prefix, err error) {
-	var str string
-	if str, err = s.readUntil([]rune{' '}, []rune{}); err != nil {
-		return
-	}
-	if len(str) == 0 {
-		return "", ErrNoPrefix
-	}
-	return Prefix(str), nil
-}
-
-// readCommand Reads the command BNF
-func (s *Scanner) readCommand() (str string, err error) {
-	if str, err = s.readUntil([]rune{' '}, []rune{}); err != nil {
-		return
-	}
-	if len(str) == 0 {
-		return "", ErrNoCommand
-	}
-	return
-}
-
-// readParams Reads the param BNF
-func (s *Scanner) readParams() ([]string, error) {
-	params := make([]string, 0)
-	for {
-		r, err := s.peekRune()
-		if err != nil {
-			return nil, fmt.Errorf("failed to peek rune due to %w", err)
-		}
-
-		if r == ' ' {
-			continue
-		}
-
-		if s.isCrlf() {
-			return params, nil
-		}
-
-		if r == ':' {
-			// Consume :
-			s.consume()
-			trailing, err := s.readParamTrailing()
-			if err != nil {
-				return nil, fmt.Errorf("failed to read trailing param due to %w", err)
-			}
-
-			params = append(params, trailing)
-			return params, nil
-		}
-
-		middle, err := s.readParamMiddle()
-		if err != nil {
-			return nil, fmt.Errorf("failed to read param middle due to %w", err)
-		}
-
-		params = append(params, middle)
-	}
-}
-
-// readParamTrailing Reads the param trailing BNF
-// returns ErrTooLong if too many runes have been read
-func (s *Scanner) readParamTrailing() (string, error) {
-	var sb strings.Builder
-	for i := 0; i < maxMessageLength; i++ {
-		if s.isCrlf() {
-			return sb.String(), nil
-		}
-
-		r := s.read()
-		if r == eof {
-			return "", io.EOF
-		}
-		sb.WriteRune(r)
+// ScanInto scans a line from the reader into the message, reusing its tags & params
+// The message is only valid until the next call, as the tags are cleared & the params overwritten
+func (s *Scanner) ScanInto(message *Message) error {
+	line, err := s.readLine()
+	if err != nil {
+		return err
 	}
-	return "", ErrTooLong
-}
-
-// readParamMiddle Reads the param middle BNF
-func (s *Scanner) readParamMiddle() (string, error) {
-	return s.readUntil([]rune{' '}, []rune{':', '\r'})
+	return ParseLine(line, message)
 }
 
-// readUntil reads runes up until is is either in the untilInclusive slice or untilExclusive
-// untilInclusive will consume the rune if it is found
-// untilExclusive will not consume the rune if it is found
-// returns ErrTooLong if too many runes have been read
-func (s *Scanner) readUntil(untilInclusive, untilExclusive []rune) (string, error) {
-	contains := func(runes []rune, r rune) bool {
-		for _, u := range runes {
-			if r == u {
-				return true
-			}
-		}
-		return false
-	}
-
-	var sb strings.Builder
-
-	for i := 0; i < maxMessageLength; i++ {
-		r, err := s.peekRune()
-		if err != nil {
-			return "", err
-		}
-		if contains(untilInclusive, r) {
-			s.consume()
-			return sb.String(), nil
+// readLine reads the next line without the CRLF, the slice is only valid until the next read
+func (s *Scanner) readLine() ([]byte, error) {
+	line, err := s.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		// Drop the rest of the line so the next Scan starts on the next message
+		for errors.Is(err, bufio.ErrBufferFull) {
+			_, err = s.ReadSlice('\n')
 		}
-		if contains(untilExclusive, r) {
-			return sb.String(), nil
-		}
-		sb.WriteRune(s.read())
-	}
-	return "", ErrTooLong
-}
-
-// read Reads and consumes a single rune from the Scanner
-func (s *Scanner) read() (r rune) {
-	var err error
-	if r, _, err = s.ReadRune(); err != nil {
-		r = eof
+		return nil, ErrTooLong
 	}
-	return
-}
-
-// consume Consumes a single rune from the Scanner with no response
-func (s *Scanner) consume() {
-	_, _, _ = s.ReadRune()
-}
-
-// peekRune Reads a single rune from the Scanner without consuming it
-func (s *Scanner) peekRune() (rune, error) {
-	r, _, err := s.ReadRune()
 	if err != nil {
-		return r, err
+		return nil, fmt.Errorf("failed to read line due to %w", err)
 	}
-	return r, s.UnreadRune()
-}
-
-// Detects if the next runes are CRLF
-func (s *Scanner) isCrlf() bool {
-	if bs, err := s.Peek(2); err != nil || bs[0] != '\r' || bs[1] != '\n' {
-		return false
+	line = line[:len(line)-1]
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
 	}
-
-	// Consume
-	_, _ = s.Read(make([]byte, 2))
-	return true
+	return line, nil
 }
@@ -25,8 +25,8 @@ func Test_ScanSmall(t *testing.T) {
 		Command: "cmd",
 		Params: []string{
 			"par1",
-			"par2",
-			" trailing ",
+			"par2:",
+			"trailing",
 		},
 	}, msg)
 }
@@ -70,6 +70,22 @@ func Test_ScanPrefix2(t *testing.T) {
 	}, msg)
 }
 
+func Test_ScanNoParams(t *testing.T) {
+	reader := strings.NewReader(":tmi.twitch.tv RECONNECT\r\n")
+	scanner := NewScanner(reader)
+
+	msg, err := scanner.Scan()
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, &Message{
+		Tags:    map[string]string{},
+		Prefix:  "tmi.twitch.tv",
+		Command: "RECONNECT",
+		Params:  []string{},
+	}, msg)
+}
+
 func Test_ScanFullInput(t *testing.T) {
 	reader := strings.NewReader("@badge-info=subscriber/8;badges=subscriber/6,bits/75000;color=#1E90FF;display-name=Ovojaytee;emotes=1837404:44-50/915234:164-169/1093027:13-18;flags=;id=aa52e1d2-6ff5-42ba-b205-9d4a15f9dbf8;login=ovojaytee;mod=0;msg-id=resub;msg-param-cumulative-months=7;msg-param-months=0;msg-param-should-share-streak=1;msg-param-streak-months=8;msg-param-sub-plan-name=Channel\\sSubscription\\s(loeya);msg-param-sub-plan=1000;room-id=166279350;subscriber=1;system-msg=Ovojaytee\\ssubscribed\\sat\\sTier\\s1.\\sThey've\\ssubscribed\\sfor\\s8\\smonths,\\scurrently\\son\\sa\\s8\\smonth\\sstreak!;tmi-sent-ts=1558352544376;user-id=160605648;user-type= :tmi.twitch.tv USERNOTICE #loeya :Wow 8 months loeyaH our baby is almost here loeyaHM can we name him Zlatan ? Thanks Queen for always starting off my day on a good note with your wonderful content loeya1\r\n")
 	scanner := NewScanner(reader)
@@ -85,6 +101,7 @@ func Test_ScanFullInput(t *testing.T) {
 			"color":                         "#1E90FF",
 			"display-name":                  "Ovojaytee",
 			"emotes":                        "1837404:44-50/915234:164-169/1093027:13-18",
+			"flags":                         "",
 			"id":                            "aa52e1d2-6ff5-42ba-b205-9d4a15f9dbf8",
 			"login":                         "ovojaytee",
 			"mod":                           "0",
@@ -100,6 +117,7 @@ func Test_ScanFullInput(t *testing.T) {
 			"system-msg":                    "Ovojaytee subscribed at Tier 1. They've subscribed for 8 months, currently on a 8 month streak!",
 			"tmi-sent-ts":                   "1558352544376",
 			"user-id":                       "160605648",
+			"user-type":                     "",
 		},
 		Prefix:  "tmi.twitch.tv",
 		Command: "USERNOTICE",
@@ -117,3 +135,11 @@ func TestScanner_Scan_Empty(t *testing.T) {
 	assert.ErrorIs(t, err, io.EOF)
 	assert.Nil(t, msg)
 }
+
+func TestParams_Channel(t *testing.T) {
+	assert.Equal(t, "channel", Params{"#channel", "message"}.Channel())
+	assert.Empty(t, Params{}.Channel())
+	assert.Empty(t, Params{""}.Channel())
+	assert.Empty(t, Params{"ab"}.Channel())
+	assert.Empty(t, Params{"#"}.Channel())
+}
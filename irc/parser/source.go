@@ -0,0 +1,54 @@
+package parser
+
+import "strings"
+
+// Source is who a message came from, either a server name or a nick with an optional user & host
+// https://modern.ircdocs.horse/#source
+type Source struct {
+	// Name is the nick of a user, or the name of a server
+	Name string `json:"name"`
+	User string `json:"user,omitempty"`
+	Host string `json:"host,omitempty"`
+}
+
+// ParseSource splits a nick!user@host or servername prefix into its parts
+func ParseSource(prefix string) Source {
+	var s Source
+	s.Name = prefix
+	if i := strings.IndexByte(s.Name, '@'); i >= 0 {
+		s.Name, s.Host = s.Name[:i], s.Name[i+1:]
+	}
+	if i := strings.IndexByte(s.Name, '!'); i >= 0 {
+		s.Name, s.User = s.Name[:i], s.Name[i+1:]
+	}
+	return s
+}
+
+// IsServer returns whether the source is a server rather than a user
+// Nicks can't contain a ., so a bare name with one is a server
+func (s Source) IsServer() bool {
+	return len(s.User) == 0 && len(s.Host) == 0 && strings.ContainsRune(s.Name, '.')
+}
+
+// Nick returns the nick of a user, or "" for a server
+func (s Source) Nick() string {
+	if s.IsServer() {
+		return ""
+	}
+	return s.Name
+}
+
+// String joins the parts back into a prefix
+func (s Source) String() string {
+	var sb strings.Builder
+	sb.WriteString(s.Name)
+	if len(s.User) > 0 {
+		sb.WriteByte('!')
+		sb.WriteString(s.User)
+	}
+	if len(s.Host) > 0 {
+		sb.WriteByte('@')
+		sb.WriteString(s.Host)
+	}
+	return sb.String()
+}
@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		expected Source
+		server   bool
+	}{
+		{prefix: "tmi.twitch.tv", expected: Source{Name: "tmi.twitch.tv"}, server: true},
+		{prefix: "bot!bot@bot.tmi.twitch.tv", expected: Source{Name: "bot", User: "bot", Host: "bot.tmi.twitch.tv"}},
+		{prefix: "nick!~user@2001:db8::1", expected: Source{Name: "nick", User: "~user", Host: "2001:db8::1"}},
+		{prefix: "nick@host", expected: Source{Name: "nick", Host: "host"}},
+		{prefix: "nick", expected: Source{Name: "nick"}},
+	}
+	for _, test := range tests {
+		t.Run(test.prefix, func(t *testing.T) {
+			s := Prefix(test.prefix).Source()
+			assert.Equal(t, test.expected, s)
+			assert.Equal(t, test.server, s.IsServer())
+			assert.Equal(t, test.prefix, s.String())
+			if test.server {
+				assert.Empty(t, s.Nick())
+			} else {
+				assert.Equal(t, s.Name, s.Nick())
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package irc
+
+import "github.com/ch629/go-irc-kafka/irc/parser"
+
+// UserCommand registers the username & real name of a new connection
+type UserCommand struct {
+	User     string
+	RealName string
+}
+
+func (c UserCommand) message() parser.Message {
+	realName := c.RealName
+	if len(realName) == 0 {
+		realName = c.User
+	}
+	return parser.Message{Command: User, Params: parser.Params{c.User, "0", "*", realName}}
+}
+
+func (c UserCommand) Bytes() []byte {
+	return c.message().Bytes()
+}
+
+// MarshalText rejects a user which would be sent as more than one param
+func (c UserCommand) MarshalText() ([]byte, error) {
+	return c.message().MarshalText()
+}
@@ -0,0 +1,21 @@
+package irc
+
+import (
+	"testing"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCommand_MarshalText(t *testing.T) {
+	line, err := UserCommand{User: "bot"}.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "USER bot 0 * bot", string(line))
+
+	line, err = UserCommand{User: "bot", RealName: "The Bot"}.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "USER bot 0 * :The Bot", string(line))
+
+	_, err = UserCommand{User: "bot 0 * injected"}.MarshalText()
+	assert.ErrorIs(t, err, parser.ErrInvalidParam)
+}
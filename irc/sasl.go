@@ -0,0 +1,110 @@
+package irc
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+)
+
+// https://ircv3.net/specs/extensions/sasl-3.1.html
+
+// saslChunkSize is the maximum length of a single AUTHENTICATE payload
+const saslChunkSize = 400
+
+var (
+	ErrSASLUnavailable          = errors.New("server doesn't support SASL")
+	ErrSASLMechanismUnsupported = errors.New("SASL mechanism not supported by server")
+)
+
+type (
+	// SASLMechanism is a single step SASL mechanism
+	SASLMechanism interface {
+		// Name is the mechanism name sent in the first AUTHENTICATE
+		Name() string
+		// Response is the raw payload sent once the server is ready, before base64 encoding
+		Response() []byte
+	}
+
+	// SASLPlain authenticates with a username & password
+	SASLPlain struct {
+		// Authzid is the optional identity to act as, defaulting to the Username
+		Authzid  string
+		Username string
+		Password string
+	}
+
+	// SASLExternal authenticates with credentials from outside of IRC, such as a TLS client certificate
+	SASLExternal struct {
+		// Authzid is the optional identity to act as
+		Authzid string
+	}
+
+	AuthenticateCommand struct {
+		Param string
+	}
+)
+
+func (SASLPlain) Name() string {
+	return "PLAIN"
+}
+
+func (p SASLPlain) Response() []byte {
+	return []byte(p.Authzid + "\x00" + p.Username + "\x00" + p.Password)
+}
+
+func (SASLExternal) Name() string {
+	return "EXTERNAL"
+}
+
+func (e SASLExternal) Response() []byte {
+	return []byte(e.Authzid)
+}
+
+func (c AuthenticateCommand) message() parser.Message {
+	return parser.Message{Command: Authenticate, Params: parser.Params{c.Param}}
+}
+
+func (c AuthenticateCommand) Bytes() []byte {
+	return c.message().Bytes()
+}
+
+// MarshalText rejects a param which isn't a single word, as it's always a mechanism, base64 or +
+func (c AuthenticateCommand) MarshalText() ([]byte, error) {
+	if len(c.Param) == 0 || c.Param[0] == ':' || strings.Contains(c.Param, " ") {
+		return nil, fmt.Errorf("%w: %q", parser.ErrInvalidParam, c.Param)
+	}
+	return c.message().MarshalText()
+}
+
+// SASLResponse encodes the mechanism's response into AUTHENTICATE commands
+// The payload is split into 400 byte chunks, with a + to mark an empty payload or end on an exact multiple of 400
+func SASLResponse(mechanism SASLMechanism) []AuthenticateCommand {
+	encoded := base64.StdEncoding.EncodeToString(mechanism.Response())
+	var commands []AuthenticateCommand
+	for len(encoded) >= saslChunkSize {
+		commands = append(commands, AuthenticateCommand{Param: encoded[:saslChunkSize]})
+		encoded = encoded[saslChunkSize:]
+	}
+	if len(encoded) == 0 {
+		encoded = "+"
+	}
+	return append(commands, AuthenticateCommand{Param: encoded})
+}
+
+// SupportsMechanism returns whether the server's sasl capability allows the mechanism
+// Servers which don't list their mechanisms are assumed to support it
+func SupportsMechanism(sasl Cap, mechanism SASLMechanism) bool {
+	mechanisms := sasl.Values()
+	if len(mechanisms) == 0 {
+		return true
+	}
+	for _, m := range mechanisms {
+		if strings.EqualFold(m, mechanism.Name()) {
+			return true
+		}
+	}
+	return false
+}
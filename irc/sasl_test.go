@@ -0,0 +1,52 @@
+package irc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSASLResponse(t *testing.T) {
+	t.Run("PLAIN", func(t *testing.T) {
+		commands := SASLResponse(SASLPlain{Username: "jilles", Password: "sesame"})
+		assert.Equal(t, []AuthenticateCommand{{Param: "AGppbGxlcwBzZXNhbWU="}}, commands)
+		assert.Equal(t, "AUTHENTICATE AGppbGxlcwBzZXNhbWU=", string(commands[0].Bytes()))
+	})
+
+	t.Run("EXTERNAL", func(t *testing.T) {
+		assert.Equal(t, []AuthenticateCommand{{Param: "+"}}, SASLResponse(SASLExternal{}))
+	})
+
+	t.Run("Chunked", func(t *testing.T) {
+		// 300 bytes encodes to exactly 400 characters, so needs a + to end
+		commands := SASLResponse(SASLExternal{Authzid: strings.Repeat("a", 300)})
+		assert.Len(t, commands, 2)
+		assert.Len(t, commands[0].Param, 400)
+		assert.Equal(t, "+", commands[1].Param)
+
+		commands = SASLResponse(SASLExternal{Authzid: strings.Repeat("a", 400)})
+		assert.Len(t, commands, 2)
+		assert.Len(t, commands[0].Param, 400)
+		assert.NotEqual(t, "+", commands[1].Param)
+	})
+}
+
+func TestSupportsMechanism(t *testing.T) {
+	assert.True(t, SupportsMechanism(Cap{Name: "sasl"}, SASLPlain{}))
+	assert.True(t, SupportsMechanism(Cap{Name: "sasl", Value: "EXTERNAL,PLAIN"}, SASLPlain{}))
+	assert.False(t, SupportsMechanism(Cap{Name: "sasl", Value: "EXTERNAL"}, SASLPlain{}))
+}
+
+func TestAuthenticateCommand_MarshalText(t *testing.T) {
+	for _, param := range []string{"PLAIN", "AGppbGxlcwBzZXNhbWU=", "+"} {
+		line, err := AuthenticateCommand{Param: param}.MarshalText()
+		assert.NoError(t, err)
+		assert.Equal(t, "AUTHENTICATE "+param, string(line))
+	}
+	for _, param := range []string{"", "PLAIN extra", ":PLAIN"} {
+		_, err := AuthenticateCommand{Param: param}.MarshalText()
+		assert.ErrorIs(t, err, parser.ErrInvalidParam, "%q", param)
+	}
+}
@@ -41,6 +41,20 @@ func (_m *Producer) SendBan(ban domain.Ban) error {
 	return r0
 }
 
+// SendChatCleared provides a mock function with given fields: cleared
+func (_m *Producer) SendChatCleared(cleared domain.ChatCleared) error {
+	ret := _m.Called(cleared)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.ChatCleared) error); ok {
+		r0 = rf(cleared)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendChatMessage provides a mock function with given fields: message
 func (_m *Producer) SendChatMessage(message domain.ChatMessage) error {
 	ret := _m.Called(message)
@@ -54,3 +68,59 @@ func (_m *Producer) SendChatMessage(message domain.ChatMessage) error {
 
 	return r0
 }
+
+// SendMembership provides a mock function with given fields: membership
+func (_m *Producer) SendMembership(membership domain.Membership) error {
+	ret := _m.Called(membership)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.Membership) error); ok {
+		r0 = rf(membership)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendMessageDeletion provides a mock function with given fields: deletion
+func (_m *Producer) SendMessageDeletion(deletion domain.MessageDeletion) error {
+	ret := _m.Called(deletion)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.MessageDeletion) error); ok {
+		r0 = rf(deletion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendRoomStateChange provides a mock function with given fields: change
+func (_m *Producer) SendRoomStateChange(change domain.RoomStateChange) error {
+	ret := _m.Called(change)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.RoomStateChange) error); ok {
+		r0 = rf(change)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendUserNotice provides a mock function with given fields: notice
+func (_m *Producer) SendUserNotice(notice domain.UserNotice) error {
+	ret := _m.Called(notice)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.UserNotice) error); ok {
+		r0 = rf(notice)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -7,15 +7,27 @@ import (
 	"github.com/Shopify/sarama"
 	"github.com/ch629/go-irc-kafka/config"
 	"github.com/ch629/go-irc-kafka/domain"
+	"github.com/ch629/go-irc-kafka/irc/parser"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// Types of message on the bans topic
+const (
+	banType         = "ban"
+	chatClearedType = "chat_cleared"
+)
+
 //go:generate mockery --name=Producer
 type (
 	Producer interface {
 		SendChatMessage(message domain.ChatMessage) error
 		SendBan(ban domain.Ban) error
+		SendChatCleared(cleared domain.ChatCleared) error
+		SendUserNotice(notice domain.UserNotice) error
+		SendMessageDeletion(deletion domain.MessageDeletion) error
+		SendRoomStateChange(change domain.RoomStateChange) error
+		SendMembership(membership domain.Membership) error
 		Close() error
 	}
 
@@ -33,6 +45,7 @@ type (
 		UserID      int       `json:"user_id"`
 		ChannelID   int       `json:"channel_id"`
 		Badges      []badge   `json:"badges"`
+		Hostmask    string    `json:"hostmask,omitempty"`
 	}
 
 	badge struct {
@@ -40,7 +53,9 @@ type (
 		Version string `json:"version"`
 	}
 
+	// banMessage & chatClearedMessage share the bans topic, discriminated by the type
 	banMessage struct {
+		Type            string         `json:"type"`
 		ChannelID       int            `json:"channel_id"`
 		TargetUserID    int            `json:"target_user_id"`
 		ChannelName     string         `json:"channel_name"`
@@ -50,6 +65,116 @@ type (
 		Permanent       bool           `json:"permanent"`
 		TargetMessageID *uuid.UUID     `json:"target_message_id,omitempty"`
 	}
+
+	chatClearedMessage struct {
+		Type        string    `json:"type"`
+		ChannelID   int       `json:"channel_id"`
+		ChannelName string    `json:"channel_name"`
+		Timestamp   time.Time `json:"timestamp"`
+	}
+
+	deletionMessage struct {
+		ChannelID       int       `json:"channel_id"`
+		ChannelName     string    `json:"channel_name"`
+		UserName        string    `json:"user_name"`
+		TargetMessageID uuid.UUID `json:"target_message_id"`
+		Message         string    `json:"message"`
+		Timestamp       time.Time `json:"timestamp"`
+	}
+
+	// membershipMessage is a JOIN or PART, with the hostmask for networks which send one
+	membershipMessage struct {
+		Type        string    `json:"type"`
+		ChannelName string    `json:"channel_name"`
+		UserName    string    `json:"user_name"`
+		Hostmask    string    `json:"hostmask"`
+		Timestamp   time.Time `json:"timestamp"`
+	}
+
+	// roomStateMessage is the settings which changed, along with every setting after the change
+	roomStateMessage struct {
+		ChannelID   int           `json:"channel_id"`
+		ChannelName string        `json:"channel_name"`
+		Changes     roomStateDiff `json:"changes"`
+		State       roomState     `json:"state"`
+	}
+
+	roomStateDiff struct {
+		EmoteOnly            *bool `json:"emote_only,omitempty"`
+		FollowersOnly        *bool `json:"followers_only,omitempty"`
+		FollowersOnlyMinutes *int  `json:"followers_only_minutes,omitempty"`
+		R9K                  *bool `json:"r9k,omitempty"`
+		SlowSeconds          *int  `json:"slow_seconds,omitempty"`
+		SubsOnly             *bool `json:"subs_only,omitempty"`
+	}
+
+	roomState struct {
+		EmoteOnly            bool `json:"emote_only"`
+		FollowersOnly        bool `json:"followers_only"`
+		FollowersOnlyMinutes int  `json:"followers_only_minutes"`
+		R9K                  bool `json:"r9k"`
+		SlowSeconds          int  `json:"slow_seconds"`
+		SubsOnly             bool `json:"subs_only"`
+	}
+
+	// eventMessage is a USERNOTICE, discriminated by the type
+	eventMessage struct {
+		Type          string         `json:"type"`
+		ID            uuid.UUID      `json:"id"`
+		ChannelName   string         `json:"channel_name"`
+		ChannelID     int            `json:"channel_id"`
+		UserName      string         `json:"user_name"`
+		DisplayName   string         `json:"display_name"`
+		UserID        int            `json:"user_id"`
+		Message       string         `json:"message,omitempty"`
+		SystemMessage string         `json:"system_message"`
+		Timestamp     time.Time      `json:"timestamp"`
+		Badges        []badge        `json:"badges"`
+		Sub           *sub           `json:"sub,omitempty"`
+		SubGift       *subGift       `json:"sub_gift,omitempty"`
+		MysteryGift   *mysteryGift   `json:"mystery_gift,omitempty"`
+		Raid          *raid          `json:"raid,omitempty"`
+		Ritual        *ritual        `json:"ritual,omitempty"`
+		BitsBadgeTier *bitsBadgeTier `json:"bits_badge_tier,omitempty"`
+	}
+
+	sub struct {
+		CumulativeMonths int    `json:"cumulative_months"`
+		StreakMonths     int    `json:"streak_months,omitempty"`
+		ShareStreak      bool   `json:"share_streak"`
+		Plan             string `json:"plan"`
+		PlanName         string `json:"plan_name"`
+	}
+
+	subGift struct {
+		Months               int    `json:"months"`
+		GiftMonths           int    `json:"gift_months,omitempty"`
+		RecipientID          int    `json:"recipient_id"`
+		RecipientUserName    string `json:"recipient_user_name"`
+		RecipientDisplayName string `json:"recipient_display_name"`
+		Plan                 string `json:"plan"`
+		PlanName             string `json:"plan_name"`
+	}
+
+	mysteryGift struct {
+		GiftCount   int    `json:"gift_count"`
+		SenderCount int    `json:"sender_count,omitempty"`
+		Plan        string `json:"plan"`
+	}
+
+	raid struct {
+		DisplayName string `json:"display_name"`
+		UserName    string `json:"user_name"`
+		ViewerCount int    `json:"viewer_count"`
+	}
+
+	ritual struct {
+		Name string `json:"name"`
+	}
+
+	bitsBadgeTier struct {
+		Threshold int `json:"threshold"`
+	}
 )
 
 func NewProducer(kafkaConfig config.Kafka) (Producer, error) {
@@ -99,6 +224,76 @@ func (producer *producer) SendBan(ban domain.Ban) error {
 	return err
 }
 
+func (producer *producer) SendUserNotice(notice domain.UserNotice) error {
+	eventMessage := mapUserNotice(notice)
+	enc, err := NewJsonEncoder(eventMessage)
+	if err != nil {
+		return err
+	}
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: fmt.Sprintf("%s.events", notice.ChannelName),
+		Key:   sarama.StringEncoder(notice.UserName),
+		Value: enc,
+	})
+	return err
+}
+
+func (producer *producer) SendChatCleared(cleared domain.ChatCleared) error {
+	chatClearedMessage := mapChatCleared(cleared)
+	enc, err := NewJsonEncoder(chatClearedMessage)
+	if err != nil {
+		return err
+	}
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: fmt.Sprintf("%s.bans", cleared.ChannelName),
+		Key:   sarama.StringEncoder(cleared.ChannelName),
+		Value: enc,
+	})
+	return err
+}
+
+func (producer *producer) SendMessageDeletion(deletion domain.MessageDeletion) error {
+	deletionMessage := mapMessageDeletion(deletion)
+	enc, err := NewJsonEncoder(deletionMessage)
+	if err != nil {
+		return err
+	}
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: fmt.Sprintf("%s.deletions", deletion.ChannelName),
+		Key:   sarama.StringEncoder(deletion.UserName),
+		Value: enc,
+	})
+	return err
+}
+
+func (producer *producer) SendRoomStateChange(change domain.RoomStateChange) error {
+	roomStateMessage := mapRoomStateChange(change)
+	enc, err := NewJsonEncoder(roomStateMessage)
+	if err != nil {
+		return err
+	}
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: fmt.Sprintf("%s.roomstate", change.State.ChannelName),
+		Key:   sarama.StringEncoder(change.State.ChannelName),
+		Value: enc,
+	})
+	return err
+}
+
+func (producer *producer) SendMembership(membership domain.Membership) error {
+	membershipMessage := mapMembership(membership)
+	enc, err := NewJsonEncoder(membershipMessage)
+	if err != nil {
+		return err
+	}
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: fmt.Sprintf("%s.membership", membership.ChannelName),
+		Key:   sarama.StringEncoder(membership.Source.Nick()),
+		Value: enc,
+	})
+	return err
+}
+
 func mapChatMessage(message domain.ChatMessage) chatMessage {
 	return chatMessage{
 		ID:          message.ID,
@@ -109,7 +304,16 @@ func mapChatMessage(message domain.ChatMessage) chatMessage {
 		UserID:      message.UserID,
 		ChannelID:   message.ChannelID,
 		Badges:      mapBadges(message.Badges),
+		Hostmask:    hostmask(message.Source),
+	}
+}
+
+// hostmask is the full nick!user@host, or empty if the prefix was only a name
+func hostmask(source parser.Source) string {
+	if len(source.User) == 0 && len(source.Host) == 0 {
+		return ""
 	}
+	return source.String()
 }
 
 func mapBadges(badges []domain.Badge) []badge {
@@ -125,6 +329,7 @@ func mapBadges(badges []domain.Badge) []badge {
 
 func mapBan(ban domain.Ban) banMessage {
 	return banMessage{
+		Type:            banType,
 		ChannelID:       ban.RoomID,
 		TargetUserID:    ban.TargetUserID,
 		ChannelName:     ban.ChannelName,
@@ -135,3 +340,121 @@ func mapBan(ban domain.Ban) banMessage {
 		TargetMessageID: ban.TargetMessageID,
 	}
 }
+
+func mapChatCleared(cleared domain.ChatCleared) chatClearedMessage {
+	return chatClearedMessage{
+		Type:        chatClearedType,
+		ChannelID:   cleared.RoomID,
+		ChannelName: cleared.ChannelName,
+		Timestamp:   cleared.Time,
+	}
+}
+
+func mapMessageDeletion(deletion domain.MessageDeletion) deletionMessage {
+	return deletionMessage{
+		ChannelID:       deletion.RoomID,
+		ChannelName:     deletion.ChannelName,
+		UserName:        deletion.UserName,
+		TargetMessageID: deletion.TargetMessageID,
+		Message:         deletion.Message,
+		Timestamp:       deletion.Time,
+	}
+}
+
+func mapMembership(membership domain.Membership) membershipMessage {
+	return membershipMessage{
+		Type:        string(membership.Type),
+		ChannelName: membership.ChannelName,
+		UserName:    membership.Source.Nick(),
+		Hostmask:    membership.Source.String(),
+		Timestamp:   membership.Time,
+	}
+}
+
+func mapRoomStateChange(change domain.RoomStateChange) roomStateMessage {
+	c, s := change.Changes, change.State
+	m := roomStateMessage{
+		ChannelID:   s.RoomID,
+		ChannelName: s.ChannelName,
+		Changes: roomStateDiff{
+			EmoteOnly:     c.EmoteOnly,
+			FollowersOnly: c.FollowersOnly,
+			R9K:           c.R9K,
+			SubsOnly:      c.SubsOnly,
+		},
+		State: roomState{
+			EmoteOnly:            s.EmoteOnly,
+			FollowersOnly:        s.FollowersOnly,
+			FollowersOnlyMinutes: int(s.FollowersOnlyDuration / time.Minute),
+			R9K:                  s.R9K,
+			SlowSeconds:          int(s.Slow / time.Second),
+			SubsOnly:             s.SubsOnly,
+		},
+	}
+	if c.FollowersOnlyDuration != nil {
+		mins := int(*c.FollowersOnlyDuration / time.Minute)
+		m.Changes.FollowersOnlyMinutes = &mins
+	}
+	if c.Slow != nil {
+		secs := int(*c.Slow / time.Second)
+		m.Changes.SlowSeconds = &secs
+	}
+	return m
+}
+
+func mapUserNotice(notice domain.UserNotice) eventMessage {
+	e := eventMessage{
+		Type:          string(notice.Type),
+		ID:            notice.ID,
+		ChannelName:   notice.ChannelName,
+		ChannelID:     notice.ChannelID,
+		UserName:      notice.UserName,
+		DisplayName:   notice.DisplayName,
+		UserID:        notice.UserID,
+		Message:       notice.Message,
+		SystemMessage: notice.SystemMessage,
+		Timestamp:     notice.Time,
+		Badges:        mapBadges(notice.Badges),
+	}
+	if s := notice.Sub; s != nil {
+		e.Sub = &sub{
+			CumulativeMonths: s.CumulativeMonths,
+			StreakMonths:     s.StreakMonths,
+			ShareStreak:      s.ShareStreak,
+			Plan:             s.Plan,
+			PlanName:         s.PlanName,
+		}
+	}
+	if g := notice.SubGift; g != nil {
+		e.SubGift = &subGift{
+			Months:               g.Months,
+			GiftMonths:           g.GiftMonths,
+			RecipientID:          g.RecipientID,
+			RecipientUserName:    g.RecipientUserName,
+			RecipientDisplayName: g.RecipientDisplayName,
+			Plan:                 g.Plan,
+			PlanName:             g.PlanName,
+		}
+	}
+	if g := notice.MysteryGift; g != nil {
+		e.MysteryGift = &mysteryGift{
+			GiftCount:   g.GiftCount,
+			SenderCount: g.SenderCount,
+			Plan:        g.Plan,
+		}
+	}
+	if r := notice.Raid; r != nil {
+		e.Raid = &raid{
+			DisplayName: r.DisplayName,
+			UserName:    r.UserName,
+			ViewerCount: r.ViewerCount,
+		}
+	}
+	if r := notice.Ritual; r != nil {
+		e.Ritual = &ritual{Name: r.Name}
+	}
+	if b := notice.BitsBadgeTier; b != nil {
+		e.BitsBadgeTier = &bitsBadgeTier{Threshold: b.Threshold}
+	}
+	return e
+}
@@ -2,18 +2,22 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"net"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ch629/go-irc-kafka/bot"
 	"github.com/ch629/go-irc-kafka/config"
 	"github.com/ch629/go-irc-kafka/domain"
+	"github.com/ch629/go-irc-kafka/irc"
 	"github.com/ch629/go-irc-kafka/irc/client"
 	"github.com/ch629/go-irc-kafka/kafka"
 	_ "github.com/ch629/go-irc-kafka/logging"
+	"github.com/ch629/go-irc-kafka/middleware"
 	"github.com/ch629/go-irc-kafka/twitch"
 	_ "github.com/dimiro1/banner/autoload"
 	"github.com/spf13/afero"
@@ -34,17 +38,13 @@ func main() {
 		log.Fatal("failed to load config", zap.Error(err))
 	}
 
-	ircClient, err := makeIrcClient(ctx, conf.Irc.Address)
-	if err != nil {
-		log.Fatal("failed to make irc client", zap.Error(err))
-	}
-
 	producer, err := kafka.NewProducer(conf.Kafka)
 	if err != nil {
 		log.Fatal("failed to create producer", zap.Error(err))
 	}
 
 	messageHandler := &bot.MessageHandler{}
+	messageHandler.Use(middleware.NewIrcLogger(log))
 
 	messageHandler.OnPrivateMessage(func(msg domain.ChatMessage) {
 		log.Debug("received private message", zap.Any("msg", msg))
@@ -58,70 +58,160 @@ func main() {
 			log.Warn("failed ot send ban message", zap.Error(err))
 		}
 	})
+	messageHandler.OnChatCleared(func(cleared domain.ChatCleared) {
+		log.Debug("received chat cleared message", zap.Any("msg", cleared))
+		if err := producer.SendChatCleared(cleared); err != nil {
+			log.Warn("failed to send chat cleared message", zap.Error(err))
+		}
+	})
+	messageHandler.OnMessageDeletion(func(deletion domain.MessageDeletion) {
+		log.Debug("received message deletion", zap.Any("msg", deletion))
+		if err := producer.SendMessageDeletion(deletion); err != nil {
+			log.Warn("failed to send message deletion", zap.Error(err))
+		}
+	})
+	messageHandler.OnRoomStateChange(func(change domain.RoomStateChange) {
+		log.Debug("received room state change", zap.Any("msg", change))
+		if err := producer.SendRoomStateChange(change); err != nil {
+			log.Warn("failed to send room state change", zap.Error(err))
+		}
+	})
+	messageHandler.OnUserNotice(func(notice domain.UserNotice) {
+		log.Debug("received user notice", zap.Any("msg", notice))
+		if err := producer.SendUserNotice(notice); err != nil {
+			log.Warn("failed to send user notice", zap.Error(err))
+		}
+	})
+	messageHandler.OnMembership(func(membership domain.Membership) {
+		log.Debug("received membership", zap.Any("msg", membership))
+		if err := producer.SendMembership(membership); err != nil {
+			log.Warn("failed to send membership", zap.Error(err))
+		}
+	})
+
+	if len(conf.Irc.Replay.Path) > 0 {
+		if err := replay(ctx, fs, conf.Irc.Replay, *messageHandler); err != nil && !errors.Is(err, context.Canceled) {
+			log.Fatal("failed to replay recording", zap.Error(err))
+		}
+		log.Info("finished replaying")
+		return
+	}
+
+	limits := conf.Irc.RateLimit
+	limiter := client.NewTwitchRateLimiter(
+		client.Limit{Count: limits.Messages, Per: limits.MessagesPer},
+		client.Limit{Count: limits.ModeratorMessages, Per: limits.ModeratorMessagesPer},
+		client.Limit{Count: limits.Joins, Per: limits.JoinsPer},
+	)
+	messageHandler.TrackModerator(limiter)
+	if limits.QueueLogInterval > 0 {
+		go logQueueDepths(ctx, limiter, limits.QueueLogInterval)
+	}
+	clientOptions := []client.Option{
+		client.WithRateLimiter(limiter),
+		client.WithKeepAlive(conf.Irc.KeepAlive.Interval, conf.Irc.KeepAlive.Timeout),
+	}
+	if len(conf.Irc.Record.Path) > 0 {
+		recording, err := client.NewRotatingFile(fs, conf.Irc.Record.Path, conf.Irc.Record.MaxSize, conf.Irc.Record.MaxFiles)
+		if err != nil {
+			log.Fatal("failed to open recording", zap.Error(err))
+		}
+		defer recording.Close()
+		clientOptions = append(clientOptions, client.WithRecorder(client.NewRecorder(recording)))
+	}
 
-	bot := bot.New(ircClient, *messageHandler)
+	backoff := bot.DefaultBackoff
+	backoff.Min = conf.Irc.Reconnect.MinBackoff
+	backoff.Max = conf.Irc.Reconnect.MaxBackoff
+	dialer, err := makeDialer(conf.Irc)
+	if err != nil {
+		log.Fatal("failed to create irc dialer", zap.Error(err))
+	}
+	sasl, err := makeSASL(conf.Irc.SASL)
+	if err != nil {
+		log.Fatal("failed to create SASL mechanism", zap.Error(err))
+	}
+	supervisor := bot.NewSupervisor(dialer, *messageHandler, bot.SupervisorConfig{
+		Name:          conf.Bot.Name,
+		OAuth:         conf.Bot.OAuth,
+		Capabilities:  []twitch.Capability{twitch.COMMANDS, twitch.MEMBERSHIP, twitch.TAGS},
+		Channels:      conf.Bot.Channels,
+		Backoff:       backoff,
+		SASL:          sasl,
+		ClientOptions: clientOptions,
+	})
 	log.Info("created bot")
 
 	go func() {
-		for err := range bot.Errors() {
+		for err := range supervisor.Errors() {
 			log.Error("err from bot", zap.Error(err))
 		}
 	}()
 
-	go bot.ProcessMessages(ctx)
-	log.Info("processing messages")
-	if err := bot.Login(ctx, conf.Bot.Name, conf.Bot.OAuth); err != nil {
-		log.Fatal("error when logging in", zap.Error(err))
+	if err := supervisor.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatal("irc connection failed", zap.Error(err))
 	}
-	log.Info("logged in successfully")
+	log.Info("closing")
+}
 
-	if err := bot.RequestCapability(twitch.COMMANDS, twitch.MEMBERSHIP, twitch.TAGS); err != nil {
-		log.Fatal("failed to request capabilities", zap.Error(err))
+func makeDialer(conf config.Irc) (client.Dialer, error) {
+	var tlsConfig *tls.Config
+	if conf.TLS.Enabled {
+		var err error
+		if tlsConfig, err = client.NewTLSConfig(conf.TLS.CAFile, conf.TLS.CertFile, conf.TLS.KeyFile); err != nil {
+			return nil, fmt.Errorf("failed to create TLS config: %w", err)
+		}
 	}
-	if err := bot.JoinChannels(conf.Bot.Channels...); err != nil {
-		log.Fatal("failed to join channels", zap.Error(err))
+	switch conf.Transport {
+	case config.TransportWebSocket:
+		return client.WebSocketDialer(conf.Address, tlsConfig), nil
+	case config.TransportTCP, "":
+		if tlsConfig != nil {
+			return client.TLSDialer(conf.Address, tlsConfig), nil
+		}
+		return client.TCPDialer(conf.Address), nil
+	default:
+		return nil, fmt.Errorf("unknown irc transport %q", conf.Transport)
 	}
-	<-ctx.Done()
-	log.Info("closing")
 }
 
-func makeIrcClient(ctx context.Context, address string) (ircClient client.IrcClient, err error) {
+// logQueueDepths periodically logs how many messages are waiting on each rate limit, until the context is done
+func logQueueDepths(ctx context.Context, limiter *client.RateLimiter, interval time.Duration) {
 	log := zap.L()
-	// Sometimes the client closes instantly, retry it 3 times
-	// TODO: Do we still need this?
-	for i := 0; i < 3; i++ {
-		conn, err := makeConnection(address)
-		if err != nil {
-			return nil, err
-		}
-		ircClient = client.NewClient(ctx, conn)
-		go ircClient.ConsumeMessages()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
 		select {
-		case <-ircClient.Done():
-			err = ircClient.Err()
-			log.Warn("IrcClient exited on startup", zap.Error(err))
-			// Make sure the connection is closed if we're retrying
-			conn.Close()
-			continue
-		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			log.Info("rate limit queue depths", zap.Any("depths", limiter.QueueDepths()))
 		}
-		break
 	}
+}
 
+// replay feeds a recording through the message handler instead of connecting to IRC
+func replay(ctx context.Context, fs afero.Fs, conf config.Replay, messageHandler bot.MessageHandler) error {
+	f, err := fs.Open(conf.Path)
 	if err != nil {
-		err = fmt.Errorf("failed to create IrcClient after retries: %w", err)
+		return fmt.Errorf("failed to open recording: %w", err)
 	}
-	return
+	defer f.Close()
+	log := zap.L()
+	return bot.Replay(ctx, f, conf.Speed, messageHandler, func(err error) {
+		log.Error("err from replay", zap.Error(err))
+	})
 }
 
-func makeConnection(address string) (*net.TCPConn, error) {
-	tcpAddr, err := net.ResolveTCPAddr("tcp4", address)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve TCP Addr %w", err)
-	}
-	conn, err := net.DialTCP("tcp", nil, tcpAddr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to Dial TCP %w", err)
+func makeSASL(conf config.SASL) (irc.SASLMechanism, error) {
+	switch strings.ToUpper(conf.Mechanism) {
+	case "":
+		return nil, nil
+	case config.SASLPlain:
+		return irc.SASLPlain{Username: conf.Username, Password: conf.Password}, nil
+	case config.SASLExternal:
+		return irc.SASLExternal{}, nil
+	default:
+		return nil, fmt.Errorf("unknown SASL mechanism %q", conf.Mechanism)
 	}
-	return conn, nil
 }
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/ch629/go-irc-kafka/bot"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"go.uber.org/zap"
+)
+
+// IrcLogger logs every inbound IRC message at debug level
+type IrcLogger struct {
+	*zap.Logger
+}
+
+func NewIrcLogger(log *zap.Logger) *IrcLogger {
+	return &IrcLogger{log}
+}
+
+func (l *IrcLogger) Handle(message parser.Message, next bot.HandlerFunc) error {
+	l.Debug("received message",
+		zap.String("command", message.Command),
+		zap.Any("message", message))
+	return next(message)
+}
+
+// Filter drops every message which keep returns false for
+func Filter(keep func(message parser.Message) bool) bot.Middleware {
+	return bot.MiddlewareFunc(func(message parser.Message, next bot.HandlerFunc) error {
+		if !keep(message) {
+			return nil
+		}
+		return next(message)
+	})
+}
+
+// Timer reports how long each message took to handle
+func Timer(observe func(command string, took time.Duration)) bot.Middleware {
+	return bot.MiddlewareFunc(func(message parser.Message, next bot.HandlerFunc) error {
+		start := time.Now()
+		err := next(message)
+		observe(message.Command, time.Since(start))
+		return err
+	})
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	privateMessages := func(message parser.Message) bool {
+		return message.Command == "PRIVMSG"
+	}
+	tests := []struct {
+		name    string
+		message parser.Message
+		handled bool
+	}{
+		{name: "Kept", message: parser.Message{Command: "PRIVMSG", Params: parser.Params{"#channel", "hi"}}, handled: true},
+		{name: "Filtered", message: parser.Message{Command: "JOIN", Params: parser.Params{"#channel"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var handled []parser.Message
+			err := Filter(privateMessages).Handle(test.message, func(message parser.Message) error {
+				handled = append(handled, message)
+				return nil
+			})
+			assert.NoError(t, err)
+			if test.handled {
+				assert.Equal(t, []parser.Message{test.message}, handled)
+			} else {
+				assert.Empty(t, handled)
+			}
+		})
+	}
+}
+
+func TestTimer(t *testing.T) {
+	tests := []struct {
+		name  string
+		sleep time.Duration
+		err   error
+	}{
+		{name: "Handled", sleep: 10 * time.Millisecond},
+		{name: "Error", err: errors.New("handler failed")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var commands []string
+			var took time.Duration
+			timer := Timer(func(command string, d time.Duration) {
+				commands = append(commands, command)
+				took = d
+			})
+			err := timer.Handle(parser.Message{Command: "PRIVMSG"}, func(parser.Message) error {
+				time.Sleep(test.sleep)
+				return test.err
+			})
+			// The handler's error is passed through, but it's still timed
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, []string{"PRIVMSG"}, commands)
+			assert.GreaterOrEqual(t, took, test.sleep)
+		})
+	}
+}
@@ -2,13 +2,38 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Service is an autogenerated mock type for the Service type
 type Service struct {
 	mock.Mock
 }
 
+// Channels provides a mock function with given fields:
+func (_m *Service) Channels() []string {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// FailJoin provides a mock function with given fields: channelName, err
+func (_m *Service) FailJoin(channelName string, err error) {
+	_m.Called(channelName, err)
+}
+
 // IsInChannel provides a mock function with given fields: channelName
 func (_m *Service) IsInChannel(channelName string) bool {
 	ret := _m.Called(channelName)
@@ -41,3 +66,33 @@ func (_m *Service) LeaveChannel(channelName string) error {
 
 	return r0
 }
+
+// ExpectJoin provides a mock function with given fields: channelName
+func (_m *Service) ExpectJoin(channelName string) func(context.Context) error {
+	ret := _m.Called(channelName)
+
+	var r0 func(context.Context) error
+	if rf, ok := ret.Get(0).(func(string) func(context.Context) error); ok {
+		r0 = rf(channelName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(func(context.Context) error)
+		}
+	}
+
+	return r0
+}
+
+// WaitForJoin provides a mock function with given fields: ctx, channelName
+func (_m *Service) WaitForJoin(ctx context.Context, channelName string) error {
+	ret := _m.Called(ctx, channelName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, channelName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,44 @@
+package state
+
+import (
+	"sync"
+
+	"github.com/ch629/go-irc-kafka/domain"
+)
+
+// RoomStates tracks the current RoomState of every channel
+type RoomStates struct {
+	mux    sync.RWMutex
+	states map[string]domain.RoomState
+}
+
+func NewRoomStates() *RoomStates {
+	return &RoomStates{
+		states: make(map[string]domain.RoomState),
+	}
+}
+
+// Update merges the update into the channel's RoomState, returning what changed
+// The first update for a channel is returned in full
+func (r *RoomStates) Update(update domain.RoomStateUpdate) domain.RoomStateChange {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	current, known := r.states[update.ChannelName]
+	state, changes := current.Apply(update)
+	if !known {
+		changes = update
+	}
+	r.states[update.ChannelName] = state
+	return domain.RoomStateChange{
+		Changes: changes,
+		State:   state,
+	}
+}
+
+// Get returns the current RoomState of the channel, if a ROOMSTATE has been received for it
+func (r *RoomStates) Get(channelName string) (domain.RoomState, bool) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	state, ok := r.states[channelName]
+	return state, ok
+}
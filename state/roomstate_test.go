@@ -0,0 +1,32 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ch629/go-irc-kafka/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoomStates_Update(t *testing.T) {
+	f := false
+	slow := 10 * time.Second
+	states := NewRoomStates()
+	first := domain.RoomStateUpdate{ChannelName: "channel", RoomID: 1, EmoteOnly: &f, SubsOnly: &f}
+
+	// The first update is returned in full, even though it matches the zero value
+	change := states.Update(first)
+	assert.Equal(t, first, change.Changes)
+	assert.True(t, change.Changes.HasChanges())
+
+	change = states.Update(domain.RoomStateUpdate{ChannelName: "channel", RoomID: 1, Slow: &slow})
+	assert.Equal(t, domain.RoomStateUpdate{ChannelName: "channel", RoomID: 1, Slow: &slow}, change.Changes)
+	assert.Equal(t, domain.RoomState{ChannelName: "channel", RoomID: 1, Slow: slow}, change.State)
+
+	state, ok := states.Get("channel")
+	assert.True(t, ok)
+	assert.Equal(t, slow, state.Slow)
+
+	_, ok = states.Get("other")
+	assert.False(t, ok)
+}
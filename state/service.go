@@ -1,9 +1,138 @@
 package state
 
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var ErrNotInChannel = errors.New("not in channel")
+
 //go:generate mockery --name=Service
 type Service interface {
-	// TODO: Where do we want to hold this state & do we actually need it?
+	// JoinChannel records that the server has confirmed we have joined the channel
 	JoinChannel(channelName string)
+	// LeaveChannel records that the server has confirmed we have left the channel
 	LeaveChannel(channelName string) error
 	IsInChannel(channelName string) bool
+	// FailJoin records that the server rejected joining the channel, returning the error to anyone waiting for the join
+	FailJoin(channelName string, err error)
+	// WaitForJoin blocks until the channel has been joined, the join fails or the context is done
+	WaitForJoin(ctx context.Context, channelName string) error
+	// ExpectJoin starts waiting for the channel before the JOIN is sent, so a fast reply can't be missed
+	// The returned function blocks like WaitForJoin
+	ExpectJoin(channelName string) func(ctx context.Context) error
+	// Channels is every channel currently joined
+	Channels() []string
+}
+
+// service tracks channel membership, channel names are case insensitive & don't include the #
+type service struct {
+	mux      sync.Mutex
+	channels map[string]struct{}
+	waiters  map[string][]chan error
+}
+
+func NewService() Service {
+	return &service{
+		channels: make(map[string]struct{}),
+		waiters:  make(map[string][]chan error),
+	}
+}
+
+func (s *service) JoinChannel(channelName string) {
+	channelName = normalise(channelName)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.channels[channelName] = struct{}{}
+	s.notify(channelName, nil)
+}
+
+func (s *service) FailJoin(channelName string, err error) {
+	channelName = normalise(channelName)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.notify(channelName, err)
+}
+
+func (s *service) LeaveChannel(channelName string) error {
+	channelName = normalise(channelName)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.channels[channelName]; !ok {
+		return ErrNotInChannel
+	}
+	delete(s.channels, channelName)
+	return nil
+}
+
+func (s *service) IsInChannel(channelName string) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	_, ok := s.channels[normalise(channelName)]
+	return ok
+}
+
+func (s *service) WaitForJoin(ctx context.Context, channelName string) error {
+	return s.ExpectJoin(channelName)(ctx)
+}
+
+func (s *service) ExpectJoin(channelName string) func(ctx context.Context) error {
+	channelName = normalise(channelName)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.channels[channelName]; ok {
+		return func(context.Context) error {
+			return nil
+		}
+	}
+	w := make(chan error, 1)
+	s.waiters[channelName] = append(s.waiters[channelName], w)
+	return func(ctx context.Context) error {
+		select {
+		case err := <-w:
+			return err
+		case <-ctx.Done():
+			s.removeWaiter(channelName, w)
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *service) Channels() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	channels := make([]string, 0, len(s.channels))
+	for ch := range s.channels {
+		channels = append(channels, ch)
+	}
+	return channels
+}
+
+// notify sends the result of a join to everyone waiting for it, the lock must be held
+func (s *service) notify(channelName string, err error) {
+	for _, w := range s.waiters[channelName] {
+		w <- err
+	}
+	delete(s.waiters, channelName)
+}
+
+func (s *service) removeWaiter(channelName string, w chan error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	waiters := s.waiters[channelName]
+	for i := range waiters {
+		if waiters[i] == w {
+			s.waiters[channelName] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(s.waiters[channelName]) == 0 {
+		delete(s.waiters, channelName)
+	}
+}
+
+func normalise(channelName string) string {
+	return strings.ToLower(strings.TrimPrefix(channelName, "#"))
 }
@@ -0,0 +1,85 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService(t *testing.T) {
+	t.Run("Join & leave", func(t *testing.T) {
+		s := NewService()
+		assert.False(t, s.IsInChannel("channel"))
+		s.JoinChannel("#Channel")
+		assert.True(t, s.IsInChannel("channel"))
+		assert.Equal(t, []string{"channel"}, s.Channels())
+		assert.NoError(t, s.LeaveChannel("channel"))
+		assert.False(t, s.IsInChannel("channel"))
+		assert.ErrorIs(t, s.LeaveChannel("channel"), ErrNotInChannel)
+	})
+
+	t.Run("Wait for join", func(t *testing.T) {
+		s := NewService()
+		errs := make(chan error)
+		go func() { errs <- s.WaitForJoin(context.Background(), "channel") }()
+		waitForWaiter(t, s, "channel")
+		s.JoinChannel("channel")
+		select {
+		case err := <-errs:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			assert.Fail(t, "WaitForJoin didn't return after join")
+		}
+		// Already joined
+		assert.NoError(t, s.WaitForJoin(context.Background(), "channel"))
+	})
+
+	t.Run("Wait for join failure", func(t *testing.T) {
+		s := NewService()
+		expected := errors.New("suspended")
+		errs := make(chan error)
+		go func() { errs <- s.WaitForJoin(context.Background(), "channel") }()
+		waitForWaiter(t, s, "channel")
+		s.FailJoin("#channel", expected)
+		select {
+		case err := <-errs:
+			assert.ErrorIs(t, err, expected)
+		case <-time.After(time.Second):
+			assert.Fail(t, "WaitForJoin didn't return after failure")
+		}
+		assert.False(t, s.IsInChannel("channel"))
+	})
+
+	t.Run("Expect join", func(t *testing.T) {
+		s := NewService()
+		expected := errors.New("suspended")
+		// The failure arrives before anything is blocking on the join
+		wait := s.ExpectJoin("channel")
+		s.FailJoin("channel", expected)
+		assert.ErrorIs(t, wait(context.Background()), expected)
+
+		s.JoinChannel("channel")
+		assert.NoError(t, s.ExpectJoin("#Channel")(context.Background()))
+	})
+
+	t.Run("Wait for join timeout", func(t *testing.T) {
+		s := NewService()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, s.WaitForJoin(ctx, "channel"), context.DeadlineExceeded)
+	})
+}
+
+// waitForWaiter blocks until something is waiting for the channel to be joined
+func waitForWaiter(t *testing.T, s Service, channelName string) {
+	t.Helper()
+	svc := s.(*service)
+	assert.Eventually(t, func() bool {
+		svc.mux.Lock()
+		defer svc.mux.Unlock()
+		return len(svc.waiters[channelName]) > 0
+	}, time.Second, time.Millisecond)
+}
@@ -1,10 +1,9 @@
 package twitch
 
 import (
-	"fmt"
-
 	"github.com/ch629/go-irc-kafka/irc"
 	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
 )
 
 type (
@@ -17,11 +16,11 @@ type (
 )
 
 func (command PassCommand) Bytes() []byte {
-	return []byte(fmt.Sprintf("%v oauth:%v", irc.Password, command.OAuth))
+	return parser.Message{Command: irc.Password, Params: parser.Params{"oauth:" + command.OAuth}}.Bytes()
 }
 
 func (command NickCommand) Bytes() []byte {
-	return []byte(fmt.Sprintf("%v %v", irc.Nickname, command.Name))
+	return parser.Message{Command: irc.Nickname, Params: parser.Params{command.Name}}.Bytes()
 }
 
 func MakePassCommand(oauth string) client.IrcMessage {
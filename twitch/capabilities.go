@@ -1,18 +1,14 @@
 package twitch
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc"
 )
 
-type (
-	Capability               int
-	capabilityRequestMessage struct {
-		Capability Capability
-	}
-)
+type Capability int
 
 const (
 	MEMBERSHIP Capability = iota
@@ -20,38 +16,71 @@ const (
 	COMMANDS
 )
 
+// capabilityPrefix is the vendor prefix of every Twitch capability
+const capabilityPrefix = "twitch.tv/"
+
+var (
+	ErrUnknownCapability = errors.New("unknown capability")
+
+	capabilityNames = []string{
+		"membership",
+		"tags",
+		"commands",
+	}
+)
+
 func (cap Capability) MarshalText() (text []byte, err error) {
 	return []byte(cap.String()), nil
 }
 
 func (cap *Capability) UnmarshalText(text []byte) error {
-	*cap = CapabilityFromParam(string(text))
+	c, ok := CapabilityFromParam(string(text))
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownCapability, text)
+	}
+	*cap = c
 	return nil
 }
 
 func (cap Capability) String() string {
-	return []string{
-		"membership",
-		"tags",
-		"commands",
-	}[cap]
+	if cap < 0 || int(cap) >= len(capabilityNames) {
+		return fmt.Sprintf("Capability(%d)", int(cap))
+	}
+	return capabilityNames[cap]
 }
 
-func CapabilityFromParam(param string) Capability {
-	capabilityName := strings.TrimPrefix(param, "twitch.tv/")
-	return map[string]Capability{
-		"membership": MEMBERSHIP,
-		"tags":       TAGS,
-		"commands":   COMMANDS,
-	}[capabilityName]
+// Name is the full IRCv3 name of the capability, including the twitch.tv/ prefix
+func (cap Capability) Name() string {
+	return capabilityPrefix + cap.String()
 }
 
-func (message capabilityRequestMessage) Bytes() []byte {
-	return []byte(fmt.Sprintf("CAP REQ :twitch.tv/%v", message.Capability.String()))
+// CapabilityFromParam finds the capability with or without the twitch.tv/ prefix, returning false if it's unknown
+func CapabilityFromParam(param string) (Capability, bool) {
+	capabilityName := strings.TrimPrefix(param, capabilityPrefix)
+	for i, name := range capabilityNames {
+		if name == capabilityName {
+			return Capability(i), true
+		}
+	}
+	return 0, false
+}
+
+// NewCapNegotiator creates an IRCv3 negotiator which requests the Twitch capabilities
+func NewCapNegotiator(capabilities ...Capability) *irc.CapNegotiator {
+	names := make([]string, len(capabilities))
+	for i, c := range capabilities {
+		names[i] = c.Name()
+	}
+	return irc.NewCapNegotiator(names...)
 }
 
-func MakeCapabilityRequest(capability Capability) client.IrcMessage {
-	return capabilityRequestMessage{
-		Capability: capability,
+// EnabledCapabilities is every Twitch capability the negotiator has had acknowledged
+func EnabledCapabilities(negotiator *irc.CapNegotiator) []Capability {
+	var caps []Capability
+	for _, c := range negotiator.EnabledCaps() {
+		if capability, ok := CapabilityFromParam(c.Name); ok && strings.HasPrefix(c.Name, capabilityPrefix) {
+			caps = append(caps, capability)
+		}
 	}
+	return caps
 }
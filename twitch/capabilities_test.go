@@ -0,0 +1,48 @@
+package twitch
+
+import (
+	"testing"
+
+	"github.com/ch629/go-irc-kafka/irc"
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilityFromParam(t *testing.T) {
+	c, ok := CapabilityFromParam("twitch.tv/tags")
+	assert.True(t, ok)
+	assert.Equal(t, TAGS, c)
+
+	c, ok = CapabilityFromParam("commands")
+	assert.True(t, ok)
+	assert.Equal(t, COMMANDS, c)
+
+	_, ok = CapabilityFromParam("twitch.tv/unknown")
+	assert.False(t, ok)
+}
+
+func TestCapability_UnmarshalText(t *testing.T) {
+	var c Capability
+	assert.NoError(t, c.UnmarshalText([]byte("membership")))
+	assert.Equal(t, MEMBERSHIP, c)
+	assert.ErrorIs(t, c.UnmarshalText([]byte("unknown")), ErrUnknownCapability)
+}
+
+func TestNewCapNegotiator(t *testing.T) {
+	n := NewCapNegotiator(TAGS, COMMANDS)
+	replies, _, err := n.Handle(parser.Message{
+		Command: "CAP",
+		Params:  []string{"*", "LS", "twitch.tv/tags twitch.tv/commands twitch.tv/membership"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []irc.CapCommand{{Subcommand: "REQ", Params: []string{"twitch.tv/tags twitch.tv/commands"}}}, replies)
+
+	_, done, err := n.Handle(parser.Message{
+		Command: "CAP",
+		Params:  []string{"*", "ACK", "twitch.tv/tags twitch.tv/commands"},
+	})
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.ElementsMatch(t, []Capability{TAGS, COMMANDS}, EnabledCapabilities(n))
+}
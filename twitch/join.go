@@ -1,10 +1,9 @@
 package twitch
 
 import (
-	"fmt"
-
 	"github.com/ch629/go-irc-kafka/irc"
 	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
 )
 
 type JoinCommand struct {
@@ -12,7 +11,7 @@ type JoinCommand struct {
 }
 
 func (command JoinCommand) Bytes() []byte {
-	return []byte(fmt.Sprintf("%v #%v", irc.Join, command.Channel))
+	return parser.Message{Command: irc.Join, Params: parser.Params{"#" + command.Channel}}.Bytes()
 }
 
 func MakeJoinCommand(channel string) client.IrcMessage {
@@ -1,9 +1,9 @@
 package twitch
 
 import (
-	"fmt"
-
+	"github.com/ch629/go-irc-kafka/irc"
 	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
 )
 
 type MessageCommand struct {
@@ -11,8 +11,17 @@ type MessageCommand struct {
 	Message string
 }
 
+func (command MessageCommand) message() parser.Message {
+	return parser.Message{Command: irc.PrivateMessage, Params: parser.Params{"#" + command.Channel, command.Message}}
+}
+
 func (command MessageCommand) Bytes() []byte {
-	return []byte(fmt.Sprintf("PRIVMSG #%v :%v", command.Channel, command.Message))
+	return command.message().Bytes()
+}
+
+// MarshalText rejects a channel which would be sent as more than one param
+func (command MessageCommand) MarshalText() ([]byte, error) {
+	return command.message().MarshalText()
 }
 
 func MakeMessageCommand(channel string, message string) client.IrcMessage {
@@ -21,3 +30,12 @@ func MakeMessageCommand(channel string, message string) client.IrcMessage {
 		Message: message,
 	}
 }
+
+// MakeReplyCommand sends a message as a threaded reply to the message with the parent id
+func MakeReplyCommand(channel, parentID, message string) client.IrcMessage {
+	return parser.Message{
+		Tags:    parser.Tags{"reply-parent-msg-id": parentID},
+		Command: irc.PrivateMessage,
+		Params:  parser.Params{"#" + channel, message},
+	}
+}
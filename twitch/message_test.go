@@ -0,0 +1,23 @@
+package twitch
+
+import (
+	"testing"
+
+	"github.com/ch629/go-irc-kafka/irc/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeReplyCommand(t *testing.T) {
+	reply := MakeReplyCommand("channel", "b34ccfc7-4977-403a-8a94-33c6bac34fb8", "thanks!")
+	assert.Equal(t, "@reply-parent-msg-id=b34ccfc7-4977-403a-8a94-33c6bac34fb8 PRIVMSG #channel thanks!", string(reply.Bytes()))
+}
+
+func TestMessageCommand_MarshalText(t *testing.T) {
+	line, err := MessageCommand{Channel: "channel", Message: "hello world"}.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "PRIVMSG #channel :hello world", string(line))
+
+	// A space in the channel would otherwise make the message text an extra param
+	_, err = MessageCommand{Channel: "channel extra", Message: "hello"}.MarshalText()
+	assert.ErrorIs(t, err, parser.ErrInvalidParam)
+}
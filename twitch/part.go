@@ -1,9 +1,9 @@
 package twitch
 
 import (
-	"fmt"
-
+	"github.com/ch629/go-irc-kafka/irc"
 	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
 )
 
 type PartCommand struct {
@@ -11,7 +11,7 @@ type PartCommand struct {
 }
 
 func (command PartCommand) Bytes() []byte {
-	return []byte(fmt.Sprintf("PART #%v", command.Channel))
+	return parser.Message{Command: irc.Part, Params: parser.Params{"#" + command.Channel}}.Bytes()
 }
 
 func MakePartCommand(channel string) client.IrcMessage {
@@ -1,10 +1,9 @@
 package twitch
 
 import (
-	"fmt"
-
 	"github.com/ch629/go-irc-kafka/irc"
 	"github.com/ch629/go-irc-kafka/irc/client"
+	"github.com/ch629/go-irc-kafka/irc/parser"
 )
 
 type PongCommand struct {
@@ -12,7 +11,7 @@ type PongCommand struct {
 }
 
 func (command PongCommand) Bytes() []byte {
-	return []byte(fmt.Sprintf("%v :%v", irc.Pong, command.Server))
+	return parser.Message{Command: irc.Pong, Params: parser.Params{command.Server}}.Bytes()
 }
 
 func MakePongCommand(server string) client.IrcMessage {